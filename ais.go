@@ -0,0 +1,128 @@
+package gpsd
+
+import "encoding/json"
+
+/*
+AISReport is a decoded AIVDM/AIVDO message of the Automatic Identification System
+
+All AIS message types are delivered as AISReport: the common fields are always present,
+the others depend on the message type and can be checked with Has. Numeric values are
+reported as transmitted unless "scaled" is enabled in the WATCH options, in which case
+gpsd converts them to engineering units (e.g. degrees for "lat" and "lon" instead of
+1/10000 minutes) and adds textual descriptions such as "status_text".
+
+example:
+
+	{"class":"AIS","device":"stdin","type":1,"repeat":0,"mmsi":265547250,"scaled":true,
+		"status":0,"status_text":"Under way using engine","turn":-2.9,"speed":13.9,
+		"accuracy":false,"lon":11.8330,"lat":57.6604,"course":40.4,"heading":41,
+		"second":53,"maneuver":0,"raim":false,"radio":114767}
+*/
+type AISReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "AIS"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Message type, 1 to 27.
+	Type int `json:"type"`
+	// Repeat indicator.
+	Repeat int `json:"repeat"`
+	// Maritime Mobile Service Identity of the source.
+	MMSI int64 `json:"mmsi"`
+	// Whether the values are scaled to engineering units.
+	Scaled bool `json:"scaled"`
+
+	// Navigation status (types 1-3, 27).
+	Status     int    `json:"status"`
+	StatusText string `json:"status_text"`
+	// Rate of turn (types 1-3). Numeric, or a string such as "nan" or "fastright" when scaled,
+	// hence left undecoded.
+	Turn json.RawMessage `json:"turn"`
+	// Speed over ground (types 1-3, 9, 18, 19, 27).
+	Speed float64 `json:"speed"`
+	// Position accuracy flag: true is high (<= 10 m).
+	Accuracy bool `json:"accuracy"`
+	// Longitude and latitude of the position.
+	Lon float64 `json:"lon"`
+	Lat float64 `json:"lat"`
+	// Course over ground.
+	Course float64 `json:"course"`
+	// True heading in degrees, 511 if not available.
+	Heading int `json:"heading"`
+	// UTC second when the report was generated.
+	Second int `json:"second"`
+	// Maneuver indicator (types 1-3).
+	Maneuver int `json:"maneuver"`
+	// RAIM flag.
+	RAIM bool `json:"raim"`
+	// Radio status, left undecoded.
+	Radio int64 `json:"radio"`
+
+	// UTC date and time of a base station report (types 4, 11).
+	Year   int `json:"year"`
+	Month  int `json:"month"`
+	Day    int `json:"day"`
+	Hour   int `json:"hour"`
+	Minute int `json:"minute"`
+	// Type of electronic position fixing device (types 4, 5, 11, 19, 21).
+	EPFD     int    `json:"epfd"`
+	EPFDText string `json:"epfd_text"`
+
+	// Static and voyage related data (types 5, 19, 24).
+	AISVersion   int     `json:"ais_version"`
+	IMO          int64   `json:"imo"`
+	Callsign     string  `json:"callsign"`
+	Shipname     string  `json:"shipname"`
+	Shiptype     int     `json:"shiptype"`
+	ShiptypeText string  `json:"shiptype_text"`
+	ToBow        int     `json:"to_bow"`
+	ToStern      int     `json:"to_stern"`
+	ToPort       int     `json:"to_port"`
+	ToStarboard  int     `json:"to_starboard"`
+	Draught      float64 `json:"draught"`
+	Destination  string  `json:"destination"`
+	DTE          int     `json:"dte"`
+
+	// Class B position report flags (types 18, 19).
+	Reserved int  `json:"reserved"`
+	Regional int  `json:"regional"`
+	CS       bool `json:"cs"`
+	Display  bool `json:"display"`
+	DSC      bool `json:"dsc"`
+	Band     bool `json:"band"`
+	Msg22    bool `json:"msg22"`
+	Assigned bool `json:"assigned"`
+
+	// Static data report (type 24).
+	PartNo         int    `json:"partno"`
+	VendorID       string `json:"vendorid"`
+	Model          int    `json:"model"`
+	Serial         int64  `json:"serial"`
+	MothershipMMSI int64  `json:"mothership_mmsi"`
+
+	// Aid-to-navigation report (type 21).
+	AidType     int    `json:"aid_type"`
+	AidTypeText string `json:"aid_type_text"`
+	Name        string `json:"name"`
+	OffPosition bool   `json:"off_position"`
+	VirtualAid  bool   `json:"virtual_aid"`
+
+	// Standard SAR aircraft position report (type 9).
+	Alt int `json:"alt"`
+
+	// Addressed and broadcast messages (types 6, 7, 8, 12, 13, 14, 25, 26).
+	SeqNo      int    `json:"seqno"`
+	DestMMSI   int64  `json:"dest_mmsi"`
+	Retransmit bool   `json:"retransmit"`
+	Text       string `json:"text"`
+	// Designated area code and functional ID of binary messages (types 6, 8).
+	DAC int `json:"dac"`
+	FID int `json:"fid"`
+	// Undecoded binary payload as "<bits>:<hex>".
+	Data string `json:"data"`
+
+	// Long range broadcast (type 27): whether the position is not GNSS based.
+	GNSS bool `json:"gnss"`
+}
@@ -0,0 +1,26 @@
+package gpsd
+
+import "testing"
+
+func TestAISReport(t *testing.T) {
+	ais := testHandled[*AISReport](t, `{"class":"AIS","device":"stdin","type":1,"repeat":0,`+
+		`"mmsi":265547250,"scaled":true,"status":0,"status_text":"Under way using engine",`+
+		`"turn":-2.9,"speed":13.9,"accuracy":false,"lon":11.8330,"lat":57.6604,"course":40.4,`+
+		`"heading":41,"second":53,"maneuver":0,"raim":false,"radio":114767}`)
+
+	if ais.Type != 1 || ais.MMSI != 265547250 || !ais.Scaled || ais.StatusText != "Under way using engine" ||
+		ais.Speed != 13.9 || ais.Lat != 57.6604 || ais.Lon != 11.8330 || ais.Heading != 41 || string(ais.Turn) != "-2.9" {
+		t.Errorf("AISReport = %+v", ais)
+	}
+	// Status 0 is present, while the fields of other message types are not.
+	for _, name := range []string{"status", "accuracy", "raim", "turn"} {
+		if !ais.Has(name) {
+			t.Errorf("Has(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"shipname", "year", "dest_mmsi", "alt"} {
+		if ais.Has(name) {
+			t.Errorf("Has(%q) = true, want false", name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gpsd
+
+import (
+	"context"
+	"encoding/json"
+)
+
+/*
+DeviceConfig is a payload of the DEVICE command used to configure a receiver
+
+Only non-zero fields are sent, the others keep their current values.
+gpsd may refuse some settings depending on the device and its driver.
+
+example:
+
+	?DEVICE={"path":"/dev/ttyUSB0","bps":9600,"parity":"N","stopbits":1,"native":1,"cycle":0.5};
+*/
+type DeviceConfig struct {
+	// Name of the device to configure. May be empty when only one device is attached.
+	Path string `json:"path,omitempty"`
+	// Device speed in bits per second.
+	Bps int `json:"bps,omitempty"`
+	// N, O or E for no parity, odd, or even.
+	Parity string `json:"parity,omitempty"`
+	// Stop bits (1 or 2).
+	Stopbits int `json:"stopbits,omitempty"`
+	// 0 means NMEA mode and 1 means alternate mode (binary if it has one, for SiRF and Evermore chipsets
+	// in particular). Nil leaves the mode unchanged.
+	Native *int `json:"native,omitempty"`
+	// Device cycle time in seconds.
+	Cycle float64 `json:"cycle,omitempty"`
+}
+
+// command returns the DEVICE command with the config as its payload.
+func (c DeviceConfig) command() string {
+	// Marshalling a struct of plain fields can't fail.
+	payload, _ := json.Marshal(c)
+	return DeviceCommand + "=" + string(payload)
+}
+
+// ConfigureDevice sends the DEVICE command to change the settings of a receiver
+// and returns its resulting state.
+func (s *Session) ConfigureDevice(ctx context.Context, cfg DeviceConfig) (*DEVICEReport, error) {
+	return Request[*DEVICEReport](ctx, s, cfg.command())
+}
+
+// Device returns the state of the device at the given path,
+// or of the only attached device if the path is empty.
+func (s *Session) Device(ctx context.Context, path string) (*DEVICEReport, error) {
+	command := DeviceCommand
+	if path != "" {
+		command = DeviceConfig{Path: path}.command()
+	}
+	return Request[*DEVICEReport](ctx, s, command)
+}
+
+// Devices sends the DEVICES command and returns the list of all devices known to gpsd.
+func (s *Session) Devices(ctx context.Context) (*DEVICESReport, error) {
+	return Request[*DEVICESReport](ctx, s, DevicesCommand)
+}
@@ -0,0 +1,79 @@
+package gpsd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownClass is wrapped by a DecodeError when a report of an unsupported class is received.
+var ErrUnknownClass = errors.New("gpsd: unknown report class")
+
+// ErrNotConnected is wrapped by a ConnError when a command is sent by a session
+// without a connection to gpsd, e.g. the session of a Replayer.
+var ErrNotConnected = errors.New("gpsd: not connected")
+
+// ErrUnexpectedLine is wrapped by a DecodeError when a line is neither a JSON object nor an NMEA sentence.
+var ErrUnexpectedLine = errors.New("gpsd: unexpected line")
+
+// DecodeError is reported when a line received from gpsd cannot be decoded.
+type DecodeError struct {
+	// Class of the report, empty if it could not be determined.
+	Class string
+	// Line is the raw line as received from gpsd.
+	Line string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Class == "" {
+		return fmt.Sprintf("gpsd: decode report: %s", e.Err)
+	}
+	return fmt.Sprintf("gpsd: decode %s report: %s", e.Class, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// ConnError is reported when reading from, writing to or dialing gpsd fails,
+// or when recording the lines read fails.
+type ConnError struct {
+	// Op is the failed operation: "dial", "read", "write" or "record".
+	Op      string
+	Address string
+	Err     error
+}
+
+func (e *ConnError) Error() string {
+	return fmt.Sprintf("gpsd: %s %s: %s", e.Op, e.Address, e.Err)
+}
+
+func (e *ConnError) Unwrap() error { return e.Err }
+
+// ProtocolError is reported when gpsd responds with an ERROR report,
+// e.g. after an unrecognised or malformed command.
+type ProtocolError struct {
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("gpsd: protocol error: %s", e.Message)
+}
+
+// OnError registers f to be called with every error encountered by the session:
+// *DecodeError, *ConnError or *ProtocolError.
+// f is called synchronously from the session goroutine, so it must not block
+// nor issue requests on the session, see Request.
+func (s *Session) OnError(f func(error)) {
+	s.errorsMu.Lock()
+	defer s.errorsMu.Unlock()
+	s.errorHandlers = append(s.errorHandlers, f)
+}
+
+func (s *Session) reportError(err error) {
+	s.errorsMu.Lock()
+	handlers := s.errorHandlers
+	s.errorsMu.Unlock()
+
+	for _, f := range handlers {
+		f(err)
+	}
+}
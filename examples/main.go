@@ -1,35 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
-	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/vpakhuchyi/go-gpsd"
+	"github.com/vpakhuchyi/go-gpsd/nmea"
 )
 
 func main() {
-	gps, err := gpsd.Dial(gpsd.DefaultAddress)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	gps, err := gpsd.DialContext(dialCtx, gpsd.DefaultAddress)
+	cancel()
 	if err != nil {
 		log.Fatalf("Failed to connect to GPSD: %s", err)
 	}
+	defer gps.Close()
 
 	gps.Subscribe("GPGGA", func(r interface{}) {
 		v := r.(string)
 		log.Printf("GPGGA sentence: %s", v)
 	})
 
-	gps.Subscribe("GPGSA", func(r interface{}) {
-		v := r.(string)
-		log.Printf("GPGSA sentence: %s", v)
+	gpsd.SubscribeNMEAFunc(gps, func(s nmea.GSA) {
+		log.Printf("GSA sentence: fix=%d satellites=%v pdop=%.1f", s.FixType, s.SV, s.PDOP)
 	})
 
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
-
-	gps.Run("nmea")
-	<-sig
-
-	gps.Close()
+	if err := gps.RunContext(ctx, "nmea"); err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("GPSD stream stopped: %s", err)
+	}
 }
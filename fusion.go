@@ -0,0 +1,364 @@
+package gpsd
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// Source identifies a receiver: a device of the gpsd at an address.
+type Source struct {
+	// Address of the session the reports were read from.
+	Address string
+	// Device path reported by gpsd, empty if the reports don't name it.
+	Device string
+}
+
+// String implements fmt.Stringer interface.
+func (s Source) String() string {
+	if s.Device == "" {
+		return s.Address
+	}
+	return s.Address + " " + s.Device
+}
+
+// Fix is a fix produced by a Fuser.
+type Fix struct {
+	// TPV of the best receiver, or a copy of it with the position, the altitudes it reports
+	// and their error estimates (eph, epv) averaged over the receivers that agree with it.
+	// It must not be modified.
+	TPV *TPVReport
+	// GST is the last error statistics of the best receiver, nil if none is recent.
+	GST *GSTReport
+	// Source is the best receiver.
+	Source Source
+	// Sources are the receivers the fix is made of, the best one first.
+	Sources []Source
+}
+
+/*
+Fuser combines the TPV and GST reports of several receivers, read from one or more
+sessions, into a single stream of the best available fix
+
+	fuser := gpsd.NewFuser()
+	fuser.Average = true
+	fuser.Attach(primary)
+	fuser.Attach(backup)
+	fuser.OnFix(func(fix gpsd.Fix) {
+		fmt.Println(fix.Source, fix.TPV.Lat, fix.TPV.Lon, fix.TPV.Eph)
+	})
+
+Receivers are told apart by the address of their session and the device of their reports.
+Among the receivers that have sent a TPV report within StaleAfter, the best one has the
+highest mode (3D over 2D), then the best FixStatus (RTK fixed, RTK float, DGPS, GPS,
+dead reckoning), then the lowest horizontal and vertical error estimates. Error estimates
+missing from a TPV report are derived for ranking from the last GST report of the receiver, if any.
+
+With Average enabled, the position of the best receiver is averaged with the ones of the
+receivers with the same mode and status whose fixes agree with it, weighted by the inverse
+square of their error estimates.
+*/
+type Fuser struct {
+	// StaleAfter is the age after which the reports of a receiver are ignored,
+	// DefaultStaleAfter by default.
+	StaleAfter time.Duration
+	// Average enables weighted averaging of the receivers that agree with the best one.
+	Average bool
+	// AgreeWithin is the maximum horizontal distance in meters between two fixes that agree.
+	// Zero means the sum of their horizontal error estimates.
+	AgreeWithin float64
+
+	// emitMu serialises fusions, so that fixes are emitted in order.
+	emitMu    sync.Mutex
+	mu        sync.Mutex
+	receivers map[Source]*receiver
+	handlers  []func(Fix)
+}
+
+// receiver holds the last reports of a Source.
+type receiver struct {
+	source  Source
+	tpv     *TPVReport
+	tpvTime time.Time
+	gst     *GSTReport
+	gstTime time.Time
+
+	// Error estimates of the last TPV report, NaN if unknown. Set by Fuser.candidates.
+	eph, epv float64
+}
+
+// NewFuser returns a Fuser without receivers.
+func NewFuser() *Fuser {
+	return &Fuser{
+		StaleAfter: DefaultStaleAfter,
+		receivers:  make(map[Source]*receiver),
+	}
+}
+
+// Attach feeds the TPV and GST reports read by the session to the fuser.
+func (f *Fuser) Attach(s *Session) {
+	SubscribeFunc(s, func(r *TPVReport) {
+		f.Update(Source{Address: s.address, Device: r.Device}, r)
+	})
+	SubscribeFunc(s, func(r *GSTReport) {
+		f.Update(Source{Address: s.address, Device: r.Device}, r)
+	})
+}
+
+// OnFix registers fn to be called with the fused fix every time a TPV report changes it.
+// fn is called synchronously from a session goroutine, so it must not block.
+func (f *Fuser) OnFix(fn func(Fix)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers = append(f.handlers, fn)
+}
+
+// Update records a report of the source, e.g. from a Replayer. Reports other than
+// *TPVReport and *GSTReport are ignored. A TPV report emits the fused fix
+// if the source is part of it.
+func (f *Fuser) Update(source Source, r Report) {
+	f.emitMu.Lock()
+	defer f.emitMu.Unlock()
+
+	now := time.Now()
+	f.mu.Lock()
+	rec := f.receivers[source]
+	if rec == nil {
+		rec = &receiver{source: source}
+		f.receivers[source] = rec
+	}
+	switch r := r.(type) {
+	case *TPVReport:
+		rec.tpv, rec.tpvTime = r, now
+	case *GSTReport:
+		rec.gst, rec.gstTime = r, now
+		f.mu.Unlock()
+		return
+	default:
+		f.mu.Unlock()
+		return
+	}
+	fix, ok := f.fuse(now)
+	handlers := f.handlers
+	f.mu.Unlock()
+
+	if !ok || !fix.contains(source) {
+		return
+	}
+	for _, fn := range handlers {
+		fn(fix)
+	}
+}
+
+// Fix returns the current fused fix, false if no receiver has a recent 2D or 3D fix.
+func (f *Fuser) Fix() (Fix, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fuse(time.Now())
+}
+
+func (x Fix) contains(source Source) bool {
+	for _, s := range x.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// fuse returns the fix of the best receivers. Called with f.mu held.
+func (f *Fuser) fuse(now time.Time) (Fix, bool) {
+	candidates := f.candidates(now)
+	if len(candidates) == 0 {
+		return Fix{}, false
+	}
+
+	best := candidates[0]
+	fix := Fix{TPV: best.tpv, Source: best.source, Sources: []Source{best.source}}
+	if best.gst != nil && now.Sub(best.gstTime) < f.staleAfter() {
+		fix.GST = best.gst
+	}
+	if !f.Average || math.IsNaN(best.eph) {
+		return fix, true
+	}
+
+	group := []*receiver{best}
+	for _, c := range candidates[1:] {
+		if c.tpv.Mode != best.tpv.Mode || statusRank(c.tpv.Status) != statusRank(best.tpv.Status) || math.IsNaN(c.eph) {
+			continue
+		}
+		limit := f.AgreeWithin
+		if limit <= 0 {
+			limit = best.eph + c.eph
+		}
+		if distance(best.tpv.Lat, best.tpv.Lon, c.tpv.Lat, c.tpv.Lon) > limit {
+			continue
+		}
+		group = append(group, c)
+		fix.Sources = append(fix.Sources, c.source)
+	}
+	if len(group) > 1 {
+		fix.TPV = average(group)
+	}
+	return fix, true
+}
+
+// candidates returns the receivers with a recent 2D or 3D fix, the best one first.
+func (f *Fuser) candidates(now time.Time) []*receiver {
+	var candidates []*receiver
+	for _, rec := range f.receivers {
+		if rec.tpv == nil || rec.tpv.Mode < Mode2D || now.Sub(rec.tpvTime) >= f.staleAfter() {
+			continue
+		}
+		rec.eph, rec.epv = math.NaN(), math.NaN()
+		if rec.tpv.Eph > 0 {
+			rec.eph = rec.tpv.Eph
+		}
+		if rec.tpv.Epv > 0 {
+			rec.epv = rec.tpv.Epv
+		}
+		// GST reports standard deviations, TPV error estimates are about twice as large.
+		if gst := rec.gst; gst != nil && now.Sub(rec.gstTime) < f.staleAfter() {
+			if math.IsNaN(rec.eph) && (gst.Lat > 0 || gst.Lon > 0) {
+				rec.eph = 2 * math.Hypot(gst.Lat, gst.Lon)
+			}
+			if math.IsNaN(rec.epv) && gst.Alt > 0 {
+				rec.epv = 2 * gst.Alt
+			}
+		}
+		candidates = append(candidates, rec)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.tpv.Mode != b.tpv.Mode {
+			return a.tpv.Mode > b.tpv.Mode
+		}
+		if ra, rb := statusRank(a.tpv.Status), statusRank(b.tpv.Status); ra != rb {
+			return ra > rb
+		}
+		if c := compareError(a.eph, b.eph); c != 0 {
+			return c < 0
+		}
+		if c := compareError(a.epv, b.epv); c != 0 {
+			return c < 0
+		}
+		if !a.tpvTime.Equal(b.tpvTime) {
+			return a.tpvTime.After(b.tpvTime)
+		}
+		// Keep the order stable across fusions.
+		return a.source.String() < b.source.String()
+	})
+	return candidates
+}
+
+func (f *Fuser) staleAfter() time.Duration {
+	if f.StaleAfter <= 0 {
+		return DefaultStaleAfter
+	}
+	return f.StaleAfter
+}
+
+// altitudes are the altitude fields of a TPV report averaged by a Fuser.
+var altitudes = []struct {
+	name  string
+	field func(*TPVReport) *float64
+}{
+	{"alt", func(r *TPVReport) *float64 { return &r.Alt }},
+	{"altHAE", func(r *TPVReport) *float64 { return &r.AltHAE }},
+	{"altMSL", func(r *TPVReport) *float64 { return &r.AltMSL }},
+}
+
+// average returns a copy of the TPV report of the first receiver with the position
+// averaged over the receivers, weighted by the inverse square of their error estimates.
+// Every altitude reported by the first receiver is averaged over the receivers reporting
+// it too, those without a vertical error estimate aside.
+func average(group []*receiver) *TPVReport {
+	best := group[0].tpv
+	tpv := *best
+
+	var sum, lat, lon float64
+	for _, rec := range group {
+		w := 1 / (rec.eph * rec.eph)
+		sum += w
+		lat += w * rec.tpv.Lat
+		// Average the differences to stay clear of the antimeridian.
+		lon += w * math.Remainder(rec.tpv.Lon-best.Lon, 360)
+	}
+	tpv.Lat = lat / sum
+	tpv.Lon = math.Remainder(best.Lon+lon/sum, 360)
+	tpv.Eph = 1 / math.Sqrt(sum)
+	fields := []string{"lat", "lon", "eph"}
+
+	if tpv.Mode == Mode3D && !math.IsNaN(group[0].epv) {
+		// The vertical error estimate is the one of the altitude averaged over the most receivers.
+		var most float64
+		for _, a := range altitudes {
+			if !best.Has(a.name) {
+				continue
+			}
+			var sum, alt float64
+			for _, rec := range group {
+				if math.IsNaN(rec.epv) || !rec.tpv.Has(a.name) {
+					continue
+				}
+				w := 1 / (rec.epv * rec.epv)
+				sum += w
+				alt += w * *a.field(rec.tpv)
+			}
+			*a.field(&tpv) = alt / sum
+			if sum > most {
+				most = sum
+			}
+		}
+		if most > 0 {
+			tpv.Epv = 1 / math.Sqrt(most)
+			fields = append(fields, "epv")
+		}
+	}
+
+	tpv.FieldSet = best.FieldSet.with(fields...)
+	return &tpv
+}
+
+// statusRank orders fix statuses by expected accuracy.
+func statusRank(s FixStatus) int {
+	switch s {
+	case StatusRTKFixed:
+		return 5
+	case StatusRTKFloat:
+		return 4
+	case StatusDGPS, StatusPY:
+		return 3
+	case StatusUnknown, StatusNormal, StatusGNSSDR, StatusSimulated:
+		return 2
+	case StatusDR:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareError compares error estimates, unknown (NaN) ones being the largest.
+func compareError(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b), a == b:
+		return 0
+	case math.IsNaN(b), a < b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// distance returns the approximate distance in meters between two close positions.
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	x := math.Remainder(lon2-lon1, 360) * rad * math.Cos((lat1+lat2)/2*rad)
+	y := (lat2 - lat1) * rad
+	return earthRadius * math.Hypot(x, y)
+}
@@ -0,0 +1,185 @@
+package gpsd
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testTPV decodes a TPV report, so that its FieldSet is the one of the line.
+func testTPV(t *testing.T, line string) *TPVReport {
+	t.Helper()
+	r, err := unmarshalReport(msgClassTPV, []byte(line))
+	if err != nil {
+		t.Fatalf("unmarshalReport(%s) error = %v", line, err)
+	}
+	return r.(*TPVReport)
+}
+
+func TestFuserCandidates(t *testing.T) {
+	now := time.Now()
+	f := NewFuser()
+	add := func(name, tpv string, gst *GSTReport, age time.Duration) {
+		source := Source{Address: name}
+		f.receivers[source] = &receiver{
+			source:  source,
+			tpv:     testTPV(t, tpv),
+			tpvTime: now.Add(-age),
+			gst:     gst,
+			gstTime: now.Add(-age),
+		}
+	}
+	add("2d", `{"class":"TPV","mode":2,"lat":1,"lon":1,"eph":0.5}`, nil, 0)
+	add("dgps", `{"class":"TPV","mode":3,"status":2,"lat":1,"lon":1,"eph":10,"epv":5}`, nil, 0)
+	add("rtk", `{"class":"TPV","mode":3,"status":3,"lat":1,"lon":1,"eph":20}`, nil, 0)
+	add("dgps-precise", `{"class":"TPV","mode":3,"status":2,"lat":1,"lon":1,"eph":5}`, nil, 0)
+	// The error estimate is derived from the GST report: 2 * hypot(3, 4).
+	add("dgps-gst", `{"class":"TPV","mode":3,"status":2,"lat":1,"lon":1}`, &GSTReport{Lat: 3, Lon: 4}, 0)
+	add("dgps-unknown", `{"class":"TPV","mode":3,"status":2,"lat":1,"lon":1}`, nil, 0)
+	add("no-fix", `{"class":"TPV","mode":1}`, nil, 0)
+	add("stale", `{"class":"TPV","mode":3,"status":3,"lat":1,"lon":1,"eph":1}`, nil, DefaultStaleAfter)
+
+	var got []string
+	for _, c := range f.candidates(now) {
+		got = append(got, c.source.Address)
+	}
+	want := []string{"rtk", "dgps-precise", "dgps", "dgps-gst", "dgps-unknown", "2d"}
+	if len(got) != len(want) {
+		t.Fatalf("candidates = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("candidates = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFuserAgreement(t *testing.T) {
+	f := NewFuser()
+	f.Average = true
+	best, near, far, dgps := Source{Address: "best"}, Source{Address: "near"}, Source{Address: "far"}, Source{Address: "dgps"}
+
+	f.Update(best, testTPV(t, `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":2}`))
+	// 1.11 m north of the best one, within the sum of the error estimates.
+	f.Update(near, testTPV(t, `{"class":"TPV","mode":3,"lat":50.00001,"lon":30,"eph":4}`))
+	// 111 m north, too far to agree.
+	f.Update(far, testTPV(t, `{"class":"TPV","mode":3,"lat":50.001,"lon":30,"eph":3}`))
+	// Close but of another status.
+	f.Update(dgps, testTPV(t, `{"class":"TPV","mode":3,"status":2,"lat":50,"lon":30,"eph":1}`))
+
+	fix, ok := f.Fix()
+	if !ok {
+		t.Fatal("Fix() = false")
+	}
+	if fix.Source != dgps || len(fix.Sources) != 1 || fix.TPV.Lat != 50 {
+		t.Errorf("Fix() = %v %v, want the DGPS receiver alone", fix.Sources, fix.TPV.Lat)
+	}
+
+	f.Update(dgps, testTPV(t, `{"class":"TPV","mode":1}`))
+	fix, _ = f.Fix()
+	if fix.Source != best || len(fix.Sources) != 2 || fix.Sources[1] != near {
+		t.Fatalf("Fix().Sources = %v, want [best near]", fix.Sources)
+	}
+	// Weights 1/4 and 1/16.
+	if want := 50 + 0.00001*(1.0/16)/(1.0/4+1.0/16); math.Abs(fix.TPV.Lat-want) > 1e-12 {
+		t.Errorf("Fix().TPV.Lat = %v, want %v", fix.TPV.Lat, want)
+	}
+	if want := 1 / math.Sqrt(1.0/4+1.0/16); math.Abs(fix.TPV.Eph-want) > 1e-12 {
+		t.Errorf("Fix().TPV.Eph = %v, want %v", fix.TPV.Eph, want)
+	}
+
+	// AgreeWithin overrides the error estimates.
+	f.AgreeWithin = 200
+	if fix, _ = f.Fix(); len(fix.Sources) != 3 {
+		t.Errorf("Fix().Sources = %v, want all three", fix.Sources)
+	}
+}
+
+func TestFuserAntimeridian(t *testing.T) {
+	f := NewFuser()
+	f.Average = true
+	f.Update(Source{Address: "east"}, testTPV(t, `{"class":"TPV","mode":2,"lat":0,"lon":179.99999,"eph":3}`))
+	f.Update(Source{Address: "west"}, testTPV(t, `{"class":"TPV","mode":2,"lat":0,"lon":-179.99999,"eph":3}`))
+
+	fix, _ := f.Fix()
+	if len(fix.Sources) != 2 {
+		t.Fatalf("Fix().Sources = %v, want both receivers 2.2 m apart", fix.Sources)
+	}
+	if d := math.Abs(math.Remainder(fix.TPV.Lon-180, 360)); d > 1e-9 {
+		t.Errorf("Fix().TPV.Lon = %v, want 180", fix.TPV.Lon)
+	}
+}
+
+func TestFuserAltitudes(t *testing.T) {
+	tests := []struct {
+		name   string
+		best   string
+		other  string
+		want   map[string]float64
+		absent []string
+		epv    float64
+	}{
+		{
+			name:  "same altitudes",
+			best:  `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":1,"epv":2,"altHAE":200,"altMSL":150}`,
+			other: `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":2,"epv":2,"altHAE":210,"altMSL":160}`,
+			want:  map[string]float64{"altHAE": 205, "altMSL": 155},
+			epv:   math.Sqrt2,
+		},
+		{
+			// The altitude of the other receiver is not averaged with altHAE nor copied.
+			name:   "other altitude",
+			best:   `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":1,"epv":2,"altHAE":200}`,
+			other:  `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":2,"epv":2,"alt":199}`,
+			want:   map[string]float64{"altHAE": 200},
+			absent: []string{"alt", "altMSL"},
+			epv:    2,
+		},
+		{
+			name:   "altitude of the best one only",
+			best:   `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":1,"epv":2,"altHAE":200,"altMSL":150}`,
+			other:  `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":2,"epv":2,"altHAE":210}`,
+			want:   map[string]float64{"altHAE": 205, "altMSL": 150},
+			absent: []string{"alt"},
+			epv:    math.Sqrt2,
+		},
+		{
+			name:   "no vertical error estimate",
+			best:   `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":1,"epv":2,"altHAE":200}`,
+			other:  `{"class":"TPV","mode":3,"lat":50,"lon":30,"eph":2,"altHAE":210}`,
+			want:   map[string]float64{"altHAE": 200},
+			absent: []string{"alt", "altMSL"},
+			epv:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFuser()
+			f.Average = true
+			f.Update(Source{Address: "best"}, testTPV(t, tt.best))
+			f.Update(Source{Address: "other"}, testTPV(t, tt.other))
+
+			fix, _ := f.Fix()
+			if len(fix.Sources) != 2 {
+				t.Fatalf("Fix().Sources = %v, want both receivers", fix.Sources)
+			}
+			for _, a := range altitudes {
+				got := *a.field(fix.TPV)
+				if want, ok := tt.want[a.name]; ok {
+					if !fix.TPV.Has(a.name) || math.Abs(got-want) > 1e-9 {
+						t.Errorf("%s = %v (present %v), want %v", a.name, got, fix.TPV.Has(a.name), want)
+					}
+				}
+			}
+			for _, name := range tt.absent {
+				if fix.TPV.Has(name) {
+					t.Errorf("%s is present, want absent", name)
+				}
+			}
+			if math.Abs(fix.TPV.Epv-tt.epv) > 1e-9 {
+				t.Errorf("Epv = %v, want %v", fix.TPV.Epv, tt.epv)
+			}
+		})
+	}
+}
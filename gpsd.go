@@ -1,17 +1,29 @@
 /*
-Package gpsd is a streaming client for GPSD's JSON service and as such can be used only in
-async manner unlike clients for other languages which support both async and sync modes.
+Package gpsd is a streaming client for GPSD's JSON service.
+
+Reports are streamed asynchronously to subscribers, while commands such as VERSION
+or POLL may be issued synchronously at the same time: their responses are routed
+back to the caller without disturbing the stream.
 */
 package gpsd
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/vpakhuchyi/go-gpsd/nmea"
+	"github.com/vpakhuchyi/go-gpsd/ubx"
 )
 
 const (
@@ -21,101 +33,381 @@ const (
 	WatchCommand   = "WATCH"
 	PollCommand    = "POLL"
 	VersionCommand = "VERSION"
+	DevicesCommand = "DEVICES"
+	DeviceCommand  = "DEVICE"
 )
 
 // Available gpsd messages formats.
 const (
 	formatJSON = "json"
 	formatNMEA = "nmea"
+	// formatMixed watches both JSON reports and NMEA sentences.
+	formatMixed = "json+nmea"
+	// formatRaw watches receiver packets, with binary ones dumped as hex.
+	formatRaw = "raw"
+	// formatSuperRaw watches receiver packets passed through as they are.
+	formatSuperRaw = "super-raw"
 )
 
 // Message classes.
 const (
-	msgClassDevices = "DEVICES"
-	msgClassPPS     = "PPS"
-	msgClassError   = "ERROR"
-	msgClassVersion = "VERSION"
-	msgClassTPV     = "TPV"
-	msgClassSKY     = "SKY"
-	msgClassGST     = "GST"
-	msgClassATT     = "ATT"
+	msgClassDevices  = "DEVICES"
+	msgClassDevice   = "DEVICE"
+	msgClassPPS      = "PPS"
+	msgClassError    = "ERROR"
+	msgClassVersion  = "VERSION"
+	msgClassTPV      = "TPV"
+	msgClassSKY      = "SKY"
+	msgClassGST      = "GST"
+	msgClassATT      = "ATT"
+	msgClassWatch    = "WATCH"
+	msgClassPoll     = "POLL"
+	msgClassTOFF     = "TOFF"
+	msgClassOSC      = "OSC"
+	msgClassIMU      = "IMU"
+	msgClassRAW      = "RAW"
+	msgClassSubframe = "SUBFRAME"
+	msgClassAIS      = "AIS"
+	msgClassRTCM2    = "RTCM2"
+	msgClassRTCM3    = "RTCM3"
+	// msgClassNMEA is a pseudo class of NMEA sentences used in errors.
+	msgClassNMEA = "NMEA"
+	// msgClassUBX is a pseudo class of UBX packets used in errors.
+	msgClassUBX = "UBX"
 )
 
+// ErrClosed is returned by RunContext once the session has been closed with Close.
+var ErrClosed = errors.New("gpsd: session closed")
+
 // Filter is a gpsd entry filter function (aka watcher or subscriber)
 type Filter func(interface{})
 
+// Option configures a Session created by Dial or DialContext.
+type Option func(*Session)
+
+// WithDialer sets the dialer used to connect and reconnect to gpsd.
+// It allows to configure a connection timeout, keep-alive or a local address.
+func WithDialer(d *net.Dialer) Option {
+	return func(s *Session) {
+		s.dialer = d
+	}
+}
+
 // Session represents a connection to gpsd
 type Session struct {
-	address string
-	socket  net.Conn
-	reader  *bufio.Reader
-	filters map[string][]Filter
-	done    chan struct{}
+	address   string
+	dialer    *net.Dialer
+	reconnect ReconnectPolicy
+	logger    Logger
+	recorder  Recorder
+
+	mu     sync.Mutex
+	socket net.Conn
+	reader *bufio.Reader
+	// readMu serialises reads of the stream and synchronous requests.
+	readMu sync.Mutex
+	// streaming is the number of running streams reading the connection.
+	streaming atomic.Int32
+
+	reqMu     sync.Mutex
+	pendingMu sync.Mutex
+	pending   *pendingRequest
+	// watch is the last WATCH command, re-sent after every reconnect.
+	// It is always the command of watchOpts, see setWatch.
+	watch string
+	// watchOpts are the options last sent with WATCH.
+	watchOpts WatchOptions
+
+	stateMu       sync.Mutex
+	state         ConnState
+	stateHandlers []func(StateEvent)
+
+	errorsMu      sync.Mutex
+	errorHandlers []func(error)
+
+	filtersMu sync.RWMutex
+	filters   map[string][]Filter
+	// sentenceFilters receive parsed NMEA sentences.
+	sentenceFilters []func(nmea.Sentence)
+	// packetFilters receive receiver packets read in raw mode.
+	packetFilters []func(Packet)
+	// ubxFilters receive decoded UBX messages.
+	ubxFilters []func(ubx.Message)
+	// taps receive every line of the JSON and NMEA streams once it has been dispatched.
+	taps []func(line string)
+	// closers release typed subscriptions once the session is closed.
+	closers []func()
+
+	// ctx is cancelled by Close and stops every running stream.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // Dial opens a new connection to GPSD.
-func Dial(address string) (*Session, error) {
-	s := &Session{address: address}
-	if err := s.dial(); err != nil {
+func Dial(address string, opts ...Option) (*Session, error) {
+	return DialContext(context.Background(), address, opts...)
+}
+
+// DialContext opens a new connection to GPSD using the provided context.
+// The context only bounds connection establishment (including reading the
+// VERSION banner); once DialContext returns, its expiration has no effect on the session.
+func DialContext(ctx context.Context, address string, opts ...Option) (*Session, error) {
+	s := newSession(address, opts...)
+
+	s.setState(StateConnecting, 0, nil)
+	if err := s.dial(ctx); err != nil {
+		s.setState(StateDisconnected, 0, err)
 		return nil, err
 	}
-	s.filters = make(map[string][]Filter)
+	s.setState(StateConnected, 0, nil)
 
 	return s, nil
 }
 
-func (s *Session) dial() error {
-	conn, err := net.Dial("tcp4", s.address)
+// newSession returns a session that is not connected yet.
+func newSession(address string, opts ...Option) *Session {
+	s := &Session{
+		address:   address,
+		dialer:    &net.Dialer{},
+		reconnect: DefaultReconnectPolicy,
+		logger:    nopLogger{},
+		filters:   make(map[string][]Filter),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	return s
+}
+
+func (s *Session) dial(ctx context.Context) error {
+	conn, err := s.dialer.DialContext(ctx, "tcp4", s.address)
+	if err != nil {
+		return err
+	}
+
+	// gpsd greets every new client with a VERSION banner.
+	// The reader is large enough to frame any packet of super-raw mode.
+	reader := bufio.NewReaderSize(conn, maxPacketSize)
+	stop := interruptOnDone(ctx, conn)
+	banner, err := reader.ReadString('\n')
+	stop()
 	if err != nil {
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return err
 	}
+	s.record(time.Now(), banner)
 
+	s.mu.Lock()
+	old := s.socket
 	s.socket = conn
-	s.reader = bufio.NewReader(conn)
-	_, err = s.reader.ReadString('\n')
-	return err
+	s.reader = reader
+	s.mu.Unlock()
+
+	if old != nil {
+		_ = old.Close()
+	}
+
+	return nil
 }
 
-// Close closes the connection to GPSD
+// conn returns the current connection and its reader.
+func (s *Session) conn() (net.Conn, *bufio.Reader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.socket, s.reader
+}
+
+// Close closes the connection to GPSD and stops the running stream, if any.
 func (s *Session) Close() error {
-	s.Watch(map[string]bool{"enable": false})
-	close(s.done)
-	return s.socket.Close()
+	s.cancel()
+	conn, _ := s.conn()
+	if conn != nil {
+		s.Watch(map[string]bool{"enable": false})
+	}
+
+	s.filtersMu.Lock()
+	closers := s.closers
+	s.closers = nil
+	s.filtersMu.Unlock()
+	for _, c := range closers {
+		c()
+	}
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
 }
 
-// Run starts monitoring the connection to GPSD
+// Run starts monitoring the connection to GPSD in the background.
+// Use RunContext to control the lifetime of the stream and to observe its terminal error.
 func (s *Session) Run(format string) {
-	go s.run(format)
+	go func() {
+		_ = s.RunContext(context.Background(), format)
+	}()
 }
 
-func (s *Session) run(format string) {
-	s.done = make(chan struct{})
+// RunContext monitors the connection to GPSD until ctx is done, the session is closed
+// or the connection is lost and the ReconnectPolicy gives up. It blocks until then and
+// returns the terminal error: ctx.Err() on cancellation, ErrClosed after Close,
+// or the error that prevented reconnecting.
+//
+// RunContext enables watching of the requested format on top of the options last
+// passed to WatchReport, e.g. to watch a single device. After every reconnect the
+// last WATCH command is sent again; subscriptions are kept by the session and keep
+// receiving reports.
+//
+// Supported formats are "json", "nmea" and "json+nmea". The latter enables both outputs:
+// JSON reports go to report subscribers and NMEA sentences to sentence subscribers,
+// e.g. to log raw NMEA while consuming TPV reports.
+//
+// The "raw" and "super-raw" formats relay the native packets of the receiver
+// (WATCH raw:1 and raw:2) to SubscribePackets subscribers, see PacketReader.
+func (s *Session) RunContext(ctx context.Context, format string) error {
+	switch format {
+	case formatJSON, formatNMEA, formatMixed, formatRaw, formatSuperRaw:
+	default:
+		return fmt.Errorf("gpsd: unsupported format %q", format)
+	}
 
-	for {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
 		select {
-		case <-s.done:
-			return
-		default:
+		case <-s.ctx.Done():
+			cancel()
+		case <-ctx.Done():
 		}
-		s.Watch(map[string]bool{"enable": true, format: true})
+	}()
+
+	s.streaming.Add(1)
+	defer s.streaming.Add(-1)
 
+	// A session that has never been connected, e.g. an endpoint of a MultiSession
+	// that was down when dialed, connects first following the reconnect policy.
+	if conn, _ := s.conn(); conn == nil {
+		if err := s.redial(ctx, ErrNotConnected); err != nil {
+			s.logger.Error("gpsd: stream stopped", "address", s.address, "error", err)
+			return err
+		}
+	}
+
+	opts := s.watchOptions()
+	opts.Enable = true
+	opts.JSON = format == formatJSON || format == formatMixed
+	opts.NMEA = format == formatNMEA || format == formatMixed
+	switch format {
+	case formatRaw:
+		opts.Raw = 1
+	case formatSuperRaw:
+		opts.Raw = 2
+	default:
+		opts.Raw = 0
+	}
+	s.SendCommand(s.setWatch(opts))
+	for {
+		var err error
 		switch format {
 		case formatJSON:
-			s.watchJSON()
-		case formatNMEA:
-			s.watchNMEA()
+			err = s.watchJSON(ctx)
+		case formatNMEA, formatMixed:
+			err = s.watchNMEA(ctx)
+		case formatRaw:
+			err = s.watchRaw(ctx)
+		case formatSuperRaw:
+			err = s.watchSuperRaw(ctx)
+		}
+		if ctx.Err() != nil {
+			return s.runErr(ctx)
+		}
+		s.logger.Warn("gpsd: connection lost", "address", s.address, "error", err)
+		s.reportError(&ConnError{Op: "read", Address: s.address, Err: err})
+		s.setState(StateDisconnected, 0, err)
+
+		if err = s.redial(ctx, err); err != nil {
+			s.logger.Error("gpsd: stream stopped", "address", s.address, "error", err)
+			return err
+		}
+		s.logger.Debug("gpsd: reconnected", "address", s.address)
+		s.restoreWatch()
+	}
+}
+
+// redial re-establishes the connection following the reconnect policy.
+// cause is the error that led to the disconnect.
+func (s *Session) redial(ctx context.Context, cause error) error {
+	err := cause
+	for attempt := 1; ; attempt++ {
+		delay, ok := s.reconnect.Next(attempt, err)
+		if !ok {
+			s.setState(StateGaveUp, attempt-1, err)
+			return fmt.Errorf("gpsd: giving up reconnecting to %s after %d attempts: %w", s.address, attempt-1, err)
 		}
 
-		time.Sleep(time.Second)
-		_ = s.dial()
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return s.runErr(ctx)
+		case <-timer.C:
+		}
+
+		s.setState(StateConnecting, attempt, nil)
+		if err = s.dial(ctx); err == nil {
+			s.setState(StateConnected, attempt, nil)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return s.runErr(ctx)
+		}
+		s.reportError(&ConnError{Op: "dial", Address: s.address, Err: err})
+		s.setState(StateDisconnected, attempt, err)
+	}
+}
+
+// runErr reports why a stream bound to ctx has stopped.
+func (s *Session) runErr(ctx context.Context) error {
+	if s.ctx.Err() != nil {
+		return ErrClosed
 	}
+	return ctx.Err()
 }
 
-// VersionSync sends the version command and returns the version response string
+// interruptOnDone unblocks a pending read on conn as soon as ctx is done.
+// The returned function releases the watcher and must always be called.
+// It clears the read deadline only if this watcher has set it, so that it never
+// erases the deadline set by another reader of the connection.
+func interruptOnDone(ctx context.Context, conn net.Conn) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	interrupted := false
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			_ = conn.SetReadDeadline(time.Now())
+			interrupted = true
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+		if interrupted {
+			_ = conn.SetReadDeadline(time.Time{})
+		}
+	}
+}
+
+// VersionSync sends the version command and returns the version response string.
+// See VersionReport for a typed and context-aware alternative.
 func (s *Session) VersionSync() string {
-	s.Version()
-	line, _ := s.readLine()
-	return line
+	return s.syncRequest(VersionCommand, msgClassVersion)
 }
 
 // Version sends the version command to GPSD
@@ -123,11 +415,10 @@ func (s *Session) Version() {
 	s.SendCommand(VersionCommand)
 }
 
-// PollSync sends the poll command returns the poll response string
+// PollSync sends the poll command returns the poll response string.
+// See PollReport for a typed and context-aware alternative.
 func (s *Session) PollSync() string {
-	s.Poll()
-	line, _ := s.readLine()
-	return line
+	return s.syncRequest(PollCommand, msgClassPoll)
 }
 
 // Poll sends the poll command to GPSD
@@ -138,140 +429,324 @@ func (s *Session) Poll() {
 // WatchSync sends the watch command with an optional param object parsed into
 // the payload and returns the watch response string
 func (s *Session) WatchSync(watchObject ...map[string]bool) string {
-	s.Watch(watchObject...)
-	line, _ := s.readLine()
-	return line
+	command := s.watchCommand(watchObject...)
+	return s.syncRequest(command, msgClassWatch)
 }
 
-// Watch sends the watch command with an optional param object parsed into the payload
+// Watch sends the watch command with an optional param object parsed into the payload.
+// Its flags are applied on top of the options last sent, e.g. by RunContext.
+// See WatchReport for typed options covering every WATCH flag.
 func (s *Session) Watch(watchObject ...map[string]bool) {
-	objectString := ""
-	if len(watchObject) == 1 {
-		var values []string
-		for k, v := range watchObject[0] {
-			values = append(values, fmt.Sprintf(`"%s":%v`, k, v))
-		}
-		objectString = fmt.Sprintf(`={%s}`, strings.Join(values, ","))
+	s.SendCommand(s.watchCommand(watchObject...))
+}
+
+// watchCommand builds the watch command. The flags of the param object are applied
+// on top of the options last sent, which are remembered to be restored after a reconnect.
+// Without a param object, the bare command only queries the current watcher policy.
+func (s *Session) watchCommand(watchObject ...map[string]bool) string {
+	if len(watchObject) != 1 {
+		return WatchCommand
 	}
-	s.SendCommand(WatchCommand + objectString)
+	return s.setWatch(s.watchOptions().apply(watchObject[0]))
 }
 
-// SendCommand sends a command to GPSD
-func (s *Session) SendCommand(command string) {
-	_, _ = fmt.Fprintf(s.socket, "?"+command+";")
+// restoreWatch sends the last WATCH command again, e.g. after a reconnect.
+func (s *Session) restoreWatch() {
+	s.mu.Lock()
+	watch := s.watch
+	s.mu.Unlock()
+
+	if watch != "" {
+		s.SendCommand(watch)
+	}
+}
+
+// SendCommand sends a command to GPSD.
+// A write failure is also reported to the OnError handlers as *ConnError.
+func (s *Session) SendCommand(command string) error {
+	conn, _ := s.conn()
+	if conn == nil {
+		err := &ConnError{Op: "write", Address: s.address, Err: ErrNotConnected}
+		s.reportError(err)
+		return err
+	}
+	if _, err := io.WriteString(conn, "?"+command+";"); err != nil {
+		err = &ConnError{Op: "write", Address: s.address, Err: err}
+		s.reportError(err)
+		return err
+	}
+	return nil
 }
 
-// SendCommandSync sends a command to GPSD and returns the response string
+// SendCommandSync sends a command to GPSD and returns the response string.
+// The response is the first report of the class named after the command, or an ERROR report.
 func (s *Session) SendCommandSync(command string) string {
-	s.SendCommand(command)
-	line, _ := s.readLine()
-	return line
+	return s.syncRequest(command, responseClass(command))
 }
 
+// AnyTalker replaces the talker ID in NMEA subscriptions matching a sentence type
+// from any talker, e.g. "--GGA" receives $GPGGA, $GNGGA, $GLGGA, $GAGGA and $BDGGA.
+const AnyTalker = "--"
+
+// Subscribe registers f to be called with every report of the given class.
+// In nmea mode the class is the address of the sentence, e.g. "GPGGA", "PUBX" or "AIVDM",
+// or AnyTalker followed by the sentence type, e.g. "--GGA".
+// See the package-level Subscribe and SubscribeFunc for type-safe alternatives.
+// f is called synchronously from the session goroutine, so it must not block
+// nor issue requests on the session, see Request.
 func (s *Session) Subscribe(class string, f Filter) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
 	s.filters[class] = append(s.filters[class], f)
 }
 
+// SubscribeAll registers f for every class that already has a subscriber.
 func (s *Session) SubscribeAll(f Filter) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
 	for class := range s.filters {
 		s.filters[class] = append(s.filters[class], f)
 	}
 }
 
+// onClose registers f to be called once the session is closed.
+func (s *Session) onClose(f func()) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	s.closers = append(s.closers, f)
+}
+
+// onLine registers f to receive every line of the JSON and NMEA streams.
+func (s *Session) onLine(f func(line string)) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	s.taps = append(s.taps, f)
+}
+
+// tap passes the line to the functions registered with onLine.
+func (s *Session) tap(line string) {
+	s.filtersMu.RLock()
+	taps := s.taps
+	s.filtersMu.RUnlock()
+	for _, f := range taps {
+		f(line)
+	}
+}
+
+// hasFilters reports whether there is at least one subscriber for the class.
+func (s *Session) hasFilters(class string) bool {
+	s.filtersMu.RLock()
+	defer s.filtersMu.RUnlock()
+	return len(s.filters[class]) > 0
+}
+
+// hasSentenceFilters reports whether there is at least one subscriber for NMEA sentences.
+func (s *Session) hasSentenceFilters() bool {
+	s.filtersMu.RLock()
+	defer s.filtersMu.RUnlock()
+	return len(s.sentenceFilters) > 0
+}
+
+// subscribeSentences registers f to be called with every parsed NMEA sentence.
+func (s *Session) subscribeSentences(f func(nmea.Sentence)) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	s.sentenceFilters = append(s.sentenceFilters, f)
+}
+
+func (s *Session) deliverSentence(sentence nmea.Sentence) {
+	s.filtersMu.RLock()
+	filters := s.sentenceFilters
+	s.filtersMu.RUnlock()
+
+	for _, f := range filters {
+		f(sentence)
+	}
+}
+
 func (s *Session) deliverReport(class string, report interface{}) {
-	for _, f := range s.filters[class] {
+	s.filtersMu.RLock()
+	filters := s.filters[class]
+	s.filtersMu.RUnlock()
+
+	for _, f := range filters {
 		f(report)
 	}
 }
 
 // readLine reads a line from the reader and returns the string
 func (s *Session) readLine() (line string, err error) {
-	line, err = s.reader.ReadString('\n')
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+
+	_, reader := s.conn()
+	line, err = reader.ReadString('\n')
+	if err == nil {
+		s.record(time.Now(), line)
+	}
 	if err != nil {
-		if err == io.EOF {
-		} else if op, ok := err.(*net.OpError); ok && strings.Contains(
-			op.Err.Error(), "use of closed network connection") {
+		if err == io.EOF || errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, net.ErrClosed) {
 		} else {
-			fmt.Printf("Stream reader error (is gpsd running?): %#v\n", err)
+			s.logger.Error("gpsd: stream read failed (is gpsd running?)", "address", s.address, "error", err)
 		}
 	}
 	return
 }
 
-// getClass returns the class string for the passed line in case of error, a blank string is returned
-func getClass(line []byte) string {
+// getClass returns the class string for the passed line
+func getClass(line []byte) (string, error) {
+	// Validating and scanning the keys is much cheaper than decoding the line,
+	// which is decoded once into its report afterwards.
+	if json.Valid(line) {
+		class, found := "", false
+		scanObject(line, func(key, value []byte) {
+			if string(key) == "class" && len(value) >= 2 && value[0] == '"' && bytes.IndexByte(value, '\\') < 0 {
+				class, found = string(value[1:len(value)-1]), true
+			}
+		})
+		if found {
+			return class, nil
+		}
+	}
+
+	// Decode to report why the line is malformed, or to handle unusual but valid lines.
 	var reportPeek gpsdReport
 	if err := json.Unmarshal(line, &reportPeek); err != nil {
-		fmt.Printf("failed to parse class type: %s\n", err)
-		return ""
+		return "", err
 	}
-	return reportPeek.Class
+	return reportPeek.Class, nil
 }
 
-func (s *Session) watchNMEA() {
+func (s *Session) watchNMEA(ctx context.Context) error {
+	conn, _ := s.conn()
+	defer interruptOnDone(ctx, conn)()
+
 	for {
-		select {
-		case <-s.done:
-			return
-		default:
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 		line, err := s.readLine()
 		if err != nil {
-			return
-		}
-
-		if _, ok := s.filters[msgClassDevices]; ok {
-			if strings.HasPrefix(line, `{"class":"DEVICES"`) {
-				report, err := unmarshalReport(msgClassDevices, []byte(line))
-				if err != nil {
-					fmt.Printf("failed to unmarshal report: %s\n", err)
-					continue
-				}
-				s.deliverReport(msgClassDevices, report)
-				continue
-			}
+			return err
 		}
 
-		// NMEA reports are prefixed with "$" that we don't need to include in the class.
-		// Next 5 characters are the class. Here is an example of a GGA report:
-		// $GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47
-		// So, line[1:6] will give us "GPGGA".ss
-		s.deliverReport(line[1:6], line)
+		s.handleLine(line)
+		s.tap(line)
 	}
 }
 
-func (s *Session) watchJSON() {
-	// We're not using a JSON decoder because we first need to inspect
-	// the JSON string to determine its "class"
+func (s *Session) watchJSON(ctx context.Context) error {
+	conn, _ := s.conn()
+	defer interruptOnDone(ctx, conn)()
+
 	for {
-		select {
-		case <-s.done:
-			return
-		default:
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 		line, err := s.readLine()
 		if err != nil {
-			return
+			return err
 		}
 
-		lineBytes := []byte(line)
-		class := getClass(lineBytes)
+		s.handleJSON(line)
+		s.tap(line)
+	}
+}
 
-		if len(s.filters[class]) == 0 {
-			continue
-		}
+// handleLine dispatches a line by its first byte: gpsd sends JSON objects
+// (e.g. responses to commands) even when watching NMEA only.
+func (s *Session) handleLine(line string) {
+	switch {
+	case strings.HasPrefix(line, "{"):
+		s.handleJSON(line)
+	case strings.HasPrefix(line, string(nmea.SentenceStart)), strings.HasPrefix(line, string(nmea.EncapsulationStart)):
+		s.handleNMEA(line)
+	default:
+		s.logger.Warn("gpsd: unexpected line", "address", s.address, "line", line)
+		s.reportError(&DecodeError{Line: line, Err: ErrUnexpectedLine})
+	}
+}
+
+func (s *Session) handleNMEA(line string) {
+	// Raw sentences are delivered under their address, e.g. "GPGGA" for
+	// $GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47,
+	// "PUBX" for $PUBX,00,... or "AIVDM" for !AIVDM,1,1,,B,...
+	// Standard sentences are also delivered under AnyTalker+type, e.g. "--GGA".
+	address, err := nmea.ParseAddress(line)
+	if err != nil {
+		s.logger.Warn("gpsd: failed to parse NMEA address", "address", s.address, "line", line, "error", err)
+		s.reportError(&DecodeError{Class: msgClassNMEA, Line: line, Err: err})
+		return
+	}
+	s.deliverReport(address.String(), line)
+	if !address.Proprietary {
+		s.deliverReport(AnyTalker+address.Type, line)
+	}
 
-		report, err := unmarshalReport(class, lineBytes)
+	if s.hasSentenceFilters() {
+		sentence, err := nmea.Parse(line)
 		if err != nil {
-			fmt.Printf("failed to unmarshal report: %s\n", err)
-			continue
+			s.logger.Warn("gpsd: failed to parse NMEA sentence", "address", s.address, "line", line, "error", err)
+			s.reportError(&DecodeError{Class: msgClassNMEA, Line: line, Err: err})
+			return
 		}
+		s.deliverSentence(sentence)
+	}
+}
 
-		s.deliverReport(class, report)
+func (s *Session) handleJSON(line string) {
+	// We're not using a JSON decoder because we first need to inspect
+	// the JSON string to determine its "class"
+	class, err := getClass([]byte(line))
+	if err != nil {
+		s.logger.Warn("gpsd: failed to parse class type", "address", s.address, "line", line, "error", err)
+		s.reportError(&DecodeError{Line: line, Err: err})
+		return
 	}
+
+	s.resolve(class, line)
+
+	// Errors are always decoded to be reported to the OnError handlers,
+	// and so are reports of unknown classes, failing with ErrUnknownClass.
+	if class != msgClassError && knownClass(class) && !s.hasFilters(class) {
+		return
+	}
+
+	s.handleReport(class, line)
 }
 
-func unmarshalReport(class string, bytes []byte) (r interface{}, err error) {
+// handleReport decodes the line as a report of the given class and delivers it to subscribers.
+func (s *Session) handleReport(class, line string) {
+	report, err := unmarshalReport(class, []byte(line))
+	if err != nil {
+		s.logger.Warn("gpsd: failed to unmarshal report",
+			"address", s.address, "class", class, "line", line, "error", err)
+		s.reportError(&DecodeError{Class: class, Line: line, Err: err})
+		return
+	}
+
+	if e, ok := report.(*ERRORReport); ok {
+		s.reportError(&ProtocolError{Message: e.Message})
+	}
+
+	s.deliverReport(class, report)
+}
+
+func unmarshalReport(class string, bytes []byte) (interface{}, error) {
+	r := newReport(class)
+	if r == nil {
+		return nil, ErrUnknownClass
+	}
+	return r, json.Unmarshal(bytes, &r)
+}
+
+// knownClass reports whether reports of the class can be decoded.
+func knownClass(class string) bool {
+	return newReport(class) != nil
+}
+
+// newReport returns a new report of the class, nil if the class is unknown.
+func newReport(class string) (r interface{}) {
 	switch class {
 	case msgClassTPV:
 		r = new(TPVReport)
@@ -285,10 +760,32 @@ func unmarshalReport(class string, bytes []byte) (r interface{}, err error) {
 		r = new(VERSIONReport)
 	case msgClassDevices:
 		r = new(DEVICESReport)
+	case msgClassDevice:
+		r = new(DEVICEReport)
 	case msgClassPPS:
 		r = new(PPSReport)
 	case msgClassError:
 		r = new(ERRORReport)
+	case msgClassPoll:
+		r = new(POLLReport)
+	case msgClassWatch:
+		r = new(WATCHReport)
+	case msgClassTOFF:
+		r = new(TOFFReport)
+	case msgClassOSC:
+		r = new(OSCReport)
+	case msgClassIMU:
+		r = new(IMUReport)
+	case msgClassRAW:
+		r = new(RAWReport)
+	case msgClassSubframe:
+		r = new(SUBFRAMEReport)
+	case msgClassAIS:
+		r = new(AISReport)
+	case msgClassRTCM2:
+		r = new(RTCM2Report)
+	case msgClassRTCM3:
+		r = new(RTCM3Report)
 	}
-	return r, json.Unmarshal(bytes, &r)
+	return r
 }
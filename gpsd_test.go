@@ -0,0 +1,437 @@
+package gpsd_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gpsd "github.com/vpakhuchyi/go-gpsd"
+	"github.com/vpakhuchyi/go-gpsd/gpsdtest"
+	"github.com/vpakhuchyi/go-gpsd/nmea"
+	"github.com/vpakhuchyi/go-gpsd/ubx"
+)
+
+// timeout bounds every wait of the tests.
+const timeout = 2 * time.Second
+
+// dial starts a fake gpsd and connects a session to it.
+func dial(t *testing.T, opts ...gpsd.Option) (*gpsdtest.Server, *gpsd.Session) {
+	t.Helper()
+	srv := gpsdtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	session, err := gpsd.Dial(srv.Addr, opts...)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return srv, session
+}
+
+// run streams the format until the end of the test and waits for the WATCH command.
+// The returned channel receives the error of RunContext.
+func run(t *testing.T, srv *gpsdtest.Server, session *gpsd.Session, format string) <-chan error {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- session.RunContext(ctx, format)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	if err := srv.WaitForCommand(timeout, "WATCH"); err != nil {
+		t.Fatal(err)
+	}
+	return done
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(timeout):
+		t.Fatalf("timed out waiting for %T", *new(T))
+		panic("unreachable")
+	}
+}
+
+// lines is a Recorder keeping the recorded lines.
+type lines struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *lines) Record(_ time.Time, line string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+	return nil
+}
+
+func TestDial(t *testing.T) {
+	recorder := &lines{}
+	srv, session := dial(t, gpsd.WithRecorder(recorder))
+
+	if session.State() != gpsd.StateConnected {
+		t.Errorf("State() = %v, want %v", session.State(), gpsd.StateConnected)
+	}
+	// The banner is consumed by Dial, so the response is the one to the request.
+	version, err := session.VersionReport(context.Background())
+	if err != nil {
+		t.Fatalf("VersionReport() error = %v", err)
+	}
+	if version.Release != "3.25" || version.ProtoMajor != 3 || version.ProtoMinor != 15 {
+		t.Errorf("VersionReport() = %+v", version)
+	}
+	srv.AssertCommands(t, "VERSION")
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.lines) != 2 || recorder.lines[0] != gpsdtest.Version+"\r\n" {
+		t.Errorf("recorded %q, want the banner and the response", recorder.lines)
+	}
+}
+
+func TestDialRefused(t *testing.T) {
+	srv := gpsdtest.NewServer()
+	srv.Close()
+
+	if _, err := gpsd.Dial(srv.Addr); err == nil {
+		t.Error("Dial() error = nil, want the connection to be refused")
+	}
+}
+
+func TestWatchReport(t *testing.T) {
+	srv, session := dial(t)
+
+	watch, err := session.WatchReport(context.Background(), gpsd.WatchOptions{Enable: true, JSON: true, Device: gpsdtest.DevicePath})
+	if err != nil {
+		t.Fatalf("WatchReport() error = %v", err)
+	}
+	if !watch.Enable || !watch.JSON || watch.NMEA {
+		t.Errorf("WatchReport() = %+v", watch)
+	}
+	srv.AssertCommands(t, `WATCH={"enable":true,"json":true,"nmea":false,"raw":0,"scaled":false,`+
+		`"split24":false,"pps":false,"timing":false,"device":"`+gpsdtest.DevicePath+`"}`)
+}
+
+func TestSendCommandVerbatim(t *testing.T) {
+	srv, session := dial(t)
+
+	// Percent signs are not format verbs.
+	if _, err := session.WatchReport(context.Background(), gpsd.WatchOptions{Enable: true, Device: "/dev/x%d"}); err != nil {
+		t.Fatalf("WatchReport() error = %v", err)
+	}
+	if _, err := session.Device(context.Background(), "/dev/serial/by-id/usb-u%2Dblox"); err != nil {
+		t.Fatalf("Device() error = %v", err)
+	}
+	srv.AssertCommands(t, `WATCH={"enable":true,"json":false,"nmea":false,"raw":0,"scaled":false,`+
+		`"split24":false,"pps":false,"timing":false,"device":"/dev/x%d"}`,
+		`DEVICE={"path":"/dev/serial/by-id/usb-u%2Dblox"}`)
+}
+
+func TestPollReport(t *testing.T) {
+	srv, session := dial(t)
+
+	poll, err := session.PollReport(context.Background())
+	if err != nil {
+		t.Fatalf("PollReport() error = %v", err)
+	}
+	if poll.Active != 1 || len(poll.TPV) != 1 {
+		t.Fatalf("PollReport() = %+v", poll)
+	}
+	tpv := poll.TPV[0]
+	if tpv.Device != gpsdtest.DevicePath || tpv.Mode != gpsd.Mode3D || tpv.Lat != 50.4501 || tpv.Lon != 30.5234 {
+		t.Errorf("PollReport().TPV[0] = %+v", tpv)
+	}
+	if !tpv.Has("altHAE") || tpv.Has("alt") {
+		t.Errorf("PollReport().TPV[0] presence of altHAE %v, alt %v", tpv.Has("altHAE"), tpv.Has("alt"))
+	}
+	srv.AssertCommands(t, "POLL")
+}
+
+func TestDevice(t *testing.T) {
+	srv, session := dial(t)
+
+	device, err := session.Device(context.Background(), gpsdtest.DevicePath)
+	if err != nil {
+		t.Fatalf("Device() error = %v", err)
+	}
+	if device.Path != gpsdtest.DevicePath {
+		t.Errorf("Device().Path = %q, want %q", device.Path, gpsdtest.DevicePath)
+	}
+
+	native := 1
+	device, err = session.ConfigureDevice(context.Background(), gpsd.DeviceConfig{Path: gpsdtest.DevicePath, Native: &native, Cycle: 0.5})
+	if err != nil {
+		t.Fatalf("ConfigureDevice() error = %v", err)
+	}
+	if device.Native != 1 || device.Cycle != 0.5 {
+		t.Errorf("ConfigureDevice() = %+v", device)
+	}
+	srv.AssertCommands(t, `DEVICE={"path":"`+gpsdtest.DevicePath+`"}`,
+		`DEVICE={"path":"`+gpsdtest.DevicePath+`","native":1,"cycle":0.5}`)
+}
+
+func TestDeviceJSONPath(t *testing.T) {
+	srv, session := dial(t)
+
+	// The path is quoted as JSON, which has no \x escapes.
+	for _, path := range []string{"/dev/gps-ñ", "/dev/gps\x01\"quoted\""} {
+		device, err := session.Device(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Device(%q) error = %v", path, err)
+		}
+		if device.Path != path {
+			t.Errorf("Device(%q).Path = %q", path, device.Path)
+		}
+	}
+	srv.AssertCommands(t, `DEVICE={"path":"/dev/gps-ñ"}`, `DEVICE={"path":"/dev/gps\u0001\"quoted\""}`)
+}
+
+func TestRequestProtocolError(t *testing.T) {
+	srv, session := dial(t)
+	srv.Respond("POLL", `{"class":"ERROR","message":"Unrecognized request 'POLL'"}`)
+
+	_, err := session.PollReport(context.Background())
+	var perr *gpsd.ProtocolError
+	if !errors.As(err, &perr) || perr.Message != "Unrecognized request 'POLL'" {
+		t.Errorf("PollReport() error = %v, want a ProtocolError", err)
+	}
+}
+
+func TestRequestTimeoutWhileStreaming(t *testing.T) {
+	srv, session := dial(t)
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](session, 4)
+	run(t, srv, session, "json")
+
+	// gpsd never answers: the request times out without disturbing the stream.
+	srv.Respond("POLL")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := session.PollReport(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("PollReport() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	srv.Send(gpsdtest.TPV)
+	if r := receive(t, tpv); r.Lat != 50.4501 {
+		t.Errorf("TPV after the timeout = %+v", r)
+	}
+	if n := srv.Connections(); n != 1 {
+		t.Errorf("%d connections, want 1", n)
+	}
+}
+
+func TestRequestFromSubscriber(t *testing.T) {
+	srv, session := dial(t)
+	// A request from a callback blocks the goroutine that would read its response.
+	errs := make(chan error, 1)
+	gpsd.SubscribeFunc(session, func(r *gpsd.TOFFReport) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := session.VersionReport(ctx)
+		errs <- err
+	})
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](session, 4)
+	run(t, srv, session, "json")
+
+	srv.Send(`{"class":"TOFF","device":"` + gpsdtest.DevicePath + `","real_sec":1,"real_nsec":0,"clock_sec":1,"clock_nsec":0}`)
+	if err := receive(t, errs); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("VersionReport() from a callback error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// A request in reaction to a report received from a channel is answered.
+	srv.Send(gpsdtest.TPV)
+	receive(t, tpv)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := session.VersionReport(ctx); err != nil {
+		t.Errorf("VersionReport() error = %v", err)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	srv, session := dial(t)
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](session, 4)
+	sky := gpsd.Subscribe[*gpsd.SKYReport](session, 4)
+	errs := make(chan error, 4)
+	session.OnError(func(err error) { errs <- err })
+	run(t, srv, session, "json")
+
+	srv.Send(gpsdtest.TPV, `{"class":"SKY","device":"`+gpsdtest.DevicePath+`","satellites":[{"PRN":7,"used":true}]}`)
+	if r := receive(t, tpv); r.Device != gpsdtest.DevicePath || r.Mode != gpsd.Mode3D || r.AltMSL != 150 {
+		t.Errorf("TPV = %+v", r)
+	}
+	if r := receive(t, sky); len(r.Satellites) != 1 || r.Satellites[0].PRN != 7 || !r.Satellites[0].Used {
+		t.Errorf("SKY = %+v", r)
+	}
+
+	tests := []struct {
+		line  string
+		class string
+		want  error
+	}{
+		{line: `{"class":"TPV",`},
+		{line: `{"class":"TPV","mode":"3D"}`, class: "TPV"},
+		{line: `{"class":"FOO"}`, class: "FOO", want: gpsd.ErrUnknownClass},
+		{line: `garbage`},
+	}
+	for _, tt := range tests {
+		srv.Send(tt.line)
+		err := receive(t, errs)
+		var derr *gpsd.DecodeError
+		if !errors.As(err, &derr) || derr.Class != tt.class || strings.TrimSpace(derr.Line) != tt.line {
+			t.Errorf("error for %s = %v, want a DecodeError of class %q", tt.line, err, tt.class)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("error for %s = %v, want %v", tt.line, err, tt.want)
+		}
+	}
+
+	// The stream goes on after malformed lines.
+	srv.Send(gpsdtest.TPV)
+	receive(t, tpv)
+}
+
+func TestRunNMEA(t *testing.T) {
+	srv, session := dial(t)
+	gga := gpsd.SubscribeNMEA[nmea.GGA](session, 4)
+	sentences := gpsd.SubscribeNMEA[nmea.Sentence](session, 4)
+	run(t, srv, session, "nmea")
+	srv.AssertCommands(t, `WATCH={"enable":true,"json":false,"nmea":true,"raw":0,"scaled":false,`+
+		`"split24":false,"pps":false,"timing":false}`)
+
+	srv.Send("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47")
+	if s := receive(t, gga); s.NumSatellites != 8 || s.Altitude != 545.4 {
+		t.Errorf("GGA = %+v", s)
+	}
+	if s := receive(t, sentences); s.DataType() != "GGA" {
+		t.Errorf("sentence = %v, want GGA", s.DataType())
+	}
+
+	errs := make(chan error, 4)
+	session.OnError(func(err error) { errs <- err })
+	tests := []struct {
+		line  string
+		class string
+		want  error
+	}{
+		{line: "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*48", class: "NMEA", want: nmea.ErrChecksum},
+		{line: "garbage", want: gpsd.ErrUnexpectedLine},
+	}
+	for _, tt := range tests {
+		srv.Send(tt.line)
+		err := receive(t, errs)
+		var derr *gpsd.DecodeError
+		if !errors.As(err, &derr) || derr.Class != tt.class || !errors.Is(err, tt.want) {
+			t.Errorf("error for %s = %v, want a DecodeError of class %q wrapping %v", tt.line, err, tt.class, tt.want)
+		}
+	}
+
+	// JSON reports are still handled while watching NMEA.
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](session, 4)
+	srv.Send(gpsdtest.TPV)
+	receive(t, tpv)
+}
+
+func TestRunSuperRaw(t *testing.T) {
+	srv, session := dial(t)
+	packets := gpsd.SubscribePackets(session, 8)
+	acks := gpsd.SubscribeUBX[ubx.Message](session, 4)
+	run(t, srv, session, "super-raw")
+
+	ack := ubx.Packet{Class: 0x05, ID: 0x01, Payload: []byte{0x06, 0x8a}}
+	gga := "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n"
+	// A false sync with a large length must not hold back the packets behind it.
+	srv.SendRaw(append(append([]byte{ubx.Sync1, ubx.Sync2, ubx.ClassNAV, ubx.IDNavPVT, 0xff, 0x7f}, gga...), ack.Bytes()...))
+
+	want := []gpsd.Packet{
+		{Protocol: gpsd.ProtocolUnknown, Data: []byte{ubx.Sync1, ubx.Sync2, ubx.ClassNAV, ubx.IDNavPVT, 0xff, 0x7f}},
+		{Protocol: gpsd.ProtocolNMEA, Data: []byte(gga)},
+		{Protocol: gpsd.ProtocolUBX, Data: ack.Bytes()},
+	}
+	for _, w := range want {
+		if p := receive(t, packets); p.Protocol != w.Protocol || string(p.Data) != string(w.Data) {
+			t.Errorf("packet = %v % x, want %v % x", p.Protocol, p.Data, w.Protocol, w.Data)
+		}
+	}
+	if m := receive(t, acks); m.MessageClass() != 0x05 || m.MessageID() != 0x01 {
+		t.Errorf("UBX message = %v, want UBX-05-01", m)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	events := make(chan gpsd.StateEvent, 16)
+	srv, session := dial(t,
+		gpsd.WithReconnectPolicy(&gpsd.Backoff{Initial: 10 * time.Millisecond, Max: 10 * time.Millisecond}),
+		gpsd.WithStateHandler(func(ev gpsd.StateEvent) { events <- ev }))
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](session, 4)
+	run(t, srv, session, "json")
+
+	srv.Disconnect()
+	for _, want := range []gpsd.ConnState{gpsd.StateDisconnected, gpsd.StateConnecting, gpsd.StateConnected} {
+		for ev := receive(t, events); ev.State != want; ev = receive(t, events) {
+			if ev.State == gpsd.StateGaveUp {
+				t.Fatalf("gave up: %v", ev.Err)
+			}
+		}
+	}
+
+	// The watch is restored on the new connection and reports keep flowing to subscribers.
+	if err := srv.WaitForConnections(timeout, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitFor(func() bool { return count(srv.Commands(), "WATCH") == 2 }); err != nil {
+		t.Fatalf("WATCH not sent again, got commands %q", srv.Commands())
+	}
+	srv.Send(gpsdtest.TPV)
+	receive(t, tpv)
+}
+
+func TestNoReconnect(t *testing.T) {
+	srv, session := dial(t, gpsd.WithReconnectPolicy(gpsd.NoReconnect))
+	done := run(t, srv, session, "json")
+
+	srv.Disconnect()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("RunContext() error = nil, want the disconnect")
+		}
+	case <-time.After(timeout):
+		t.Fatal("RunContext() did not return")
+	}
+	if session.State() != gpsd.StateGaveUp {
+		t.Errorf("State() = %v, want %v", session.State(), gpsd.StateGaveUp)
+	}
+}
+
+func count(commands []string, name string) int {
+	n := 0
+	for _, c := range commands {
+		if gpsdtest.Match(c, name) {
+			n++
+		}
+	}
+	return n
+}
+
+// waitFor polls cond until it is true or the timeout expires.
+func waitFor(cond func() bool) error {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return errors.New("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+/*
+Package gpsdserver implements a minimal gpsd endpoint speaking the JSON protocol over TCP.
+
+The server greets every client with a VERSION banner and answers the ?VERSION, ?WATCH,
+?POLL, ?DEVICES and ?DEVICE commands on behalf of a single device. Reports pushed
+with Send are written to every connected client:
+
+	srv, err := gpsdserver.Listen("localhost:2947", gpsdserver.WithDevice("/dev/ttyACM0"))
+	defer srv.Close()
+
+	srv.HandleFunc("POLL", poll)
+	srv.Send(`{"class":"TPV","mode":3,"lat":50.45,"lon":30.52}`)
+
+It is the core of the fake gpsd of the gpsdtest package and of the simulation server
+of the sim package. Watch settings sent by the clients are acknowledged but not
+enforced: every client receives everything that is sent.
+*/
+package gpsdserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DevicePath is the path of the device reported by default.
+	DevicePath = "/dev/ttyGPS0"
+	// Version is the VERSION report sent as banner and in response to ?VERSION by default.
+	Version = `{"class":"VERSION","release":"3.25","rev":"3.25","proto_major":3,"proto_minor":15}`
+	// Poll is the POLL report sent in response to ?POLL by default, without any fix.
+	Poll = `{"class":"POLL","time":"1970-01-01T00:00:00.000Z","active":0,"tpv":[],"gst":[],"sky":[]}`
+)
+
+// Devices returns the DEVICES report listing the device at the path,
+// sent in response to ?DEVICES and ?WATCH.
+func Devices(path string) string {
+	return `{"class":"DEVICES","devices":[{"class":"DEVICE","path":"` + path + `",` +
+		`"driver":"NMEA0183","activated":"2026-01-01T00:00:00.000Z","flags":1,"native":0,` +
+		`"bps":9600,"parity":"N","stopbits":1,"cycle":1.00}]}`
+}
+
+// HandlerFunc returns the lines to send in response to a command. payload is the part
+// of the command after '=', e.g. `{"enable":true}` for `?WATCH={"enable":true};`.
+type HandlerFunc func(payload string) []string
+
+// Option configures a Server created by Listen.
+type Option func(*Server)
+
+// WithBanner sets the line sent to every client once it connects. Empty disables the banner.
+func WithBanner(line string) Option {
+	return func(s *Server) {
+		s.banner = line
+	}
+}
+
+// WithLatency delays every line sent by the server.
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) {
+		s.latency = d
+	}
+}
+
+// WithDevice sets the path of the device listed in the DEVICES report, DevicePath by default.
+func WithDevice(path string) Option {
+	return func(s *Server) {
+		s.device = path
+	}
+}
+
+// WithConnectHook sets f to be called whenever a client connects, before the banner is sent.
+func WithConnectHook(f func()) Option {
+	return func(s *Server) {
+		s.onConnect = f
+	}
+}
+
+// WithCommandHook sets f to be called with every command received, before it is answered.
+// The command comes without the leading '?' and trailing ';', e.g. `WATCH={"enable":true}`.
+func WithCommandHook(f func(command string)) Option {
+	return func(s *Server) {
+		s.onCommand = f
+	}
+}
+
+// Server is a gpsd endpoint listening on a TCP port.
+type Server struct {
+	// Addr is the address of the server in the form host:port, to be passed to gpsd.Dial.
+	Addr string
+
+	ln        net.Listener
+	banner    string
+	device    string
+	onConnect func()
+	onCommand func(string)
+	wg        sync.WaitGroup
+
+	mu          sync.Mutex
+	latency     time.Duration
+	handlers    map[string]HandlerFunc
+	conns       map[*conn]struct{}
+	connections int
+}
+
+// Listen starts a server listening on the TCP address, e.g. "localhost:2947".
+func Listen(address string, opts ...Option) (*Server, error) {
+	ln, err := net.Listen("tcp4", address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Addr:   ln.Addr().String(),
+		ln:     ln,
+		banner: Version,
+		device: DevicePath,
+		conns:  make(map[*conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	devices := Devices(s.device)
+	s.handlers = map[string]HandlerFunc{
+		"VERSION": func(string) []string { return []string{Version} },
+		"DEVICES": func(string) []string { return []string{devices} },
+		"WATCH":   func(payload string) []string { return []string{devices, echo("WATCH", payload)} },
+		"DEVICE":  func(payload string) []string { return []string{echo("DEVICE", payload)} },
+		"POLL":    func(string) []string { return []string{Poll} },
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// echo returns the report of the class with the fields of the payload, like gpsd does for WATCH and DEVICE.
+func echo(class, payload string) string {
+	fields := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(payload), &fields)
+	fields["class"] = class
+	// Marshalling decoded JSON can't fail.
+	line, _ := json.Marshal(fields)
+	return string(line)
+}
+
+// Close disconnects every client and stops the server.
+func (s *Server) Close() {
+	_ = s.ln.Close()
+	s.Disconnect()
+	s.wg.Wait()
+}
+
+// Respond replaces the response to the command, e.g. "POLL", with the given lines.
+// No lines makes the server ignore the command.
+func (s *Server) Respond(command string, lines ...string) {
+	s.HandleFunc(command, func(string) []string { return lines })
+}
+
+// HandleFunc sets the handler of the command, e.g. "WATCH".
+func (s *Server) HandleFunc(command string, f HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[command] = f
+}
+
+// SetLatency delays every line sent by the server from now on.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Send writes the lines, e.g. JSON reports, NMEA sentences or malformed data,
+// to every connected client. A line terminator is appended to each of them.
+func (s *Server) Send(lines ...string) {
+	for _, c := range s.clients() {
+		c.send(lines...)
+	}
+}
+
+// SendRaw writes the data as it is to every connected client, e.g. binary packets.
+func (s *Server) SendRaw(data []byte) {
+	for _, c := range s.clients() {
+		c.write(data)
+	}
+}
+
+// Disconnect drops every connected client. The server keeps accepting new connections.
+func (s *Server) Disconnect() {
+	for _, c := range s.clients() {
+		_ = c.Close()
+	}
+}
+
+// Connections returns the number of connections accepted so far.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// CommandName returns the name of the command, e.g. "WATCH" for `WATCH={"enable":true}`.
+func CommandName(command string) string {
+	name, _, _ := strings.Cut(command, "=")
+	return name
+}
+
+func (s *Server) clients() []*conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clients := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		c := &conn{Conn: nc, server: s}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.connections++
+		s.mu.Unlock()
+		if s.onConnect != nil {
+			s.onConnect()
+		}
+
+		s.wg.Add(1)
+		go c.serve()
+	}
+}
+
+// conn is a client connection.
+type conn struct {
+	net.Conn
+	server *Server
+	mu     sync.Mutex
+}
+
+func (c *conn) serve() {
+	defer c.server.wg.Done()
+	defer func() {
+		_ = c.Close()
+		c.server.mu.Lock()
+		delete(c.server.conns, c)
+		c.server.mu.Unlock()
+	}()
+
+	s := c.server
+	if s.banner != "" {
+		c.send(s.banner)
+	}
+
+	r := bufio.NewReader(c)
+	for {
+		command, err := r.ReadString(';')
+		if err != nil {
+			return
+		}
+		command = strings.TrimSpace(command)
+		command = strings.TrimSuffix(strings.TrimPrefix(command, "?"), ";")
+		if s.onCommand != nil {
+			s.onCommand(command)
+		}
+
+		name, payload, _ := strings.Cut(command, "=")
+		s.mu.Lock()
+		handler, ok := s.handlers[name]
+		s.mu.Unlock()
+
+		if !ok {
+			c.send(fmt.Sprintf(`{"class":"ERROR","message":"Unrecognized request '%s'"}`, name))
+			continue
+		}
+		c.send(handler(payload)...)
+	}
+}
+
+func (c *conn) send(lines ...string) {
+	for _, line := range lines {
+		c.write([]byte(line + "\r\n"))
+	}
+}
+
+func (c *conn) write(data []byte) {
+	c.server.mu.Lock()
+	latency := c.server.latency
+	c.server.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	time.Sleep(latency)
+	if _, err := c.Write(data); err != nil && !errors.Is(err, net.ErrClosed) {
+		_ = c.Close()
+	}
+}
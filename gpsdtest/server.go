@@ -0,0 +1,197 @@
+/*
+Package gpsdtest provides an in-process fake gpsd server for tests.
+
+The server greets every client with a VERSION banner, answers the ?VERSION, ?WATCH,
+?POLL, ?DEVICES and ?DEVICE commands with canned responses, and records every
+command it receives. Tests push reports, malformed lines or binary data to the
+connected clients, add latency and drop connections to exercise reconnects:
+
+	srv := gpsdtest.NewServer()
+	defer srv.Close()
+
+	session, err := gpsd.Dial(srv.Addr)
+	...
+	go session.RunContext(ctx, "json")
+	srv.WaitForCommand(time.Second, "WATCH")
+	srv.Send(`{"class":"TPV","mode":3,"lat":50.45,"lon":30.52}`)
+	srv.Send(`{"class":"TPV",`) // malformed
+	srv.Disconnect()
+
+The server is a gpsdserver.Server reporting DevicePath, with a canned POLL response.
+*/
+package gpsdtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vpakhuchyi/go-gpsd/gpsdserver"
+)
+
+// Canned responses of the server.
+const (
+	// DevicePath is the path of the device reported by the server.
+	DevicePath = "/dev/gpsdtest"
+	// Version is the VERSION report sent as banner and in response to ?VERSION.
+	Version = gpsdserver.Version
+	// TPV is the TPV report included in the response to ?POLL.
+	TPV = `{"class":"TPV","device":"` + DevicePath + `","mode":3,"time":"2026-01-01T00:00:00.000Z",` +
+		`"ept":0.005,"lat":50.450100,"lon":30.523400,"altHAE":179.000,"altMSL":150.000,` +
+		`"epx":3.000,"epy":3.000,"epv":5.000,"track":0.0000,"speed":0.000,"climb":0.000}`
+)
+
+// Devices is the DEVICES report sent in response to ?DEVICES and ?WATCH.
+var Devices = gpsdserver.Devices(DevicePath)
+
+// HandlerFunc returns the lines to send in response to a command. payload is the part
+// of the command after '=', e.g. `{"enable":true}` for `?WATCH={"enable":true};`.
+type HandlerFunc = gpsdserver.HandlerFunc
+
+// Option configures a Server created by NewServer.
+type Option = gpsdserver.Option
+
+// WithBanner sets the line sent to every client once it connects. Empty disables the banner.
+func WithBanner(line string) Option {
+	return gpsdserver.WithBanner(line)
+}
+
+// WithLatency delays every line sent by the server.
+func WithLatency(d time.Duration) Option {
+	return gpsdserver.WithLatency(d)
+}
+
+// Server is a fake gpsd listening on a local TCP port. Besides serving clients like
+// a gpsdserver.Server, it records the commands it receives for the test to wait for them.
+type Server struct {
+	*gpsdserver.Server
+
+	mu       sync.Mutex
+	commands []string
+	// changed is closed and replaced whenever a command is received or a client connects.
+	changed chan struct{}
+}
+
+// NewServer starts a server listening on a random local port. It panics if it can't listen.
+func NewServer(opts ...Option) *Server {
+	s, err := Listen("127.0.0.1:0", opts...)
+	if err != nil {
+		panic(fmt.Sprintf("gpsdtest: failed to listen: %v", err))
+	}
+	return s
+}
+
+// Listen starts a server listening on the TCP address, e.g. "localhost:2947".
+func Listen(address string, opts ...Option) (*Server, error) {
+	s := &Server{changed: make(chan struct{})}
+	opts = append([]Option{
+		gpsdserver.WithDevice(DevicePath),
+		gpsdserver.WithConnectHook(s.connected),
+		gpsdserver.WithCommandHook(s.received),
+	}, opts...)
+
+	srv, err := gpsdserver.Listen(address, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.Server = srv
+	s.Respond("POLL", `{"class":"POLL","time":"2026-01-01T00:00:00.000Z","active":1,"tpv":[`+TPV+`],"gst":[],"sky":[]}`)
+	return s, nil
+}
+
+func (s *Server) connected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify()
+}
+
+func (s *Server) received(command string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, command)
+	s.notify()
+}
+
+// WaitForConnections waits until at least n connections have been accepted.
+func (s *Server) WaitForConnections(timeout time.Duration, n int) error {
+	return s.wait(timeout, func() bool { return s.Connections() >= n },
+		func() string { return fmt.Sprintf("%d connections, got %d", n, s.Connections()) })
+}
+
+// Commands returns the commands received so far without the leading '?' and trailing ';',
+// e.g. `WATCH={"enable":true,"json":true}`.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// WaitForCommand waits until a command matching name has been received, see Match.
+func (s *Server) WaitForCommand(timeout time.Duration, name string) error {
+	return s.wait(timeout, func() bool {
+		for _, c := range s.commands {
+			if Match(c, name) {
+				return true
+			}
+		}
+		return false
+	}, func() string { return fmt.Sprintf("command %q, got %q", name, s.commands) })
+}
+
+// TB is the part of testing.TB used by AssertCommands, so that the package
+// can be imported by programs without linking the testing package.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertCommands fails the test unless the commands received so far match want in order, see Match.
+func (s *Server) AssertCommands(t TB, want ...string) {
+	t.Helper()
+	got := s.Commands()
+	if len(got) != len(want) {
+		t.Errorf("gpsdtest: got commands %q, want %q", got, want)
+		return
+	}
+	for i := range want {
+		if !Match(got[i], want[i]) {
+			t.Errorf("gpsdtest: got commands %q, want %q", got, want)
+			return
+		}
+	}
+}
+
+// Match reports whether the command matches want: either exactly, or by name
+// when want has no payload, e.g. "WATCH" matches `WATCH={"enable":true}`.
+func Match(command, want string) bool {
+	return command == want || (!strings.Contains(want, "=") && gpsdserver.CommandName(command) == want)
+}
+
+// wait waits until cond, evaluated with the server locked, is true.
+func (s *Server) wait(timeout time.Duration, cond func() bool, describe func() string) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		ok, changed := cond(), s.changed
+		s.mu.Unlock()
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return fmt.Errorf("gpsdtest: timed out waiting for %s", describe())
+		}
+	}
+}
+
+// notify wakes up the waiters. The server must be locked.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
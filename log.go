@@ -0,0 +1,26 @@
+package gpsd
+
+// Logger is a minimal structured logger used by Session to report stream problems.
+// Arguments are alternating key/value pairs, so *slog.Logger satisfies it directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithLogger sets the logger of the session. Sessions are silent by default.
+func WithLogger(l Logger) Option {
+	return func(s *Session) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		s.logger = l
+	}
+}
+
+// nopLogger discards everything.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
@@ -0,0 +1,339 @@
+package gpsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultStaleAfter is the time without TPV reports after which an endpoint of a MultiSession is considered silent.
+const DefaultStaleAfter = 3 * time.Second
+
+// minHealthCheckInterval bounds how often Run checks for silent endpoints, a quarter of StaleAfter.
+const minHealthCheckInterval = time.Millisecond
+
+// SwitchReason tells why a MultiSession has changed its active source.
+type SwitchReason int
+
+const (
+	// SwitchInitial is the selection of the first source with a fix.
+	SwitchInitial SwitchReason = iota
+	// SwitchSilent is a failover from a source that has been disconnected or has stopped sending TPV reports.
+	SwitchSilent
+	// SwitchLostFix is a failover from a source that has lost its fix.
+	SwitchLostFix
+	// SwitchFailback is a return to a source of higher priority that has recovered.
+	SwitchFailback
+)
+
+// String implements fmt.Stringer interface.
+func (r SwitchReason) String() string {
+	switch r {
+	case SwitchInitial:
+		return "initial"
+	case SwitchSilent:
+		return "silent"
+	case SwitchLostFix:
+		return "lost fix"
+	case SwitchFailback:
+		return "failback"
+	default:
+		return "unknown"
+	}
+}
+
+// SourceEvent describes a change of the active source of a MultiSession.
+type SourceEvent struct {
+	// Address of the gpsd that is now active.
+	Address string
+	// Previous is the address of the gpsd that was active, empty for the initial selection.
+	Previous string
+	Reason   SwitchReason
+}
+
+/*
+MultiSession follows several gpsd instances, e.g. a primary and a backup one,
+and relays the reports of a single one of them, the active source
+
+	multi, err := gpsd.DialMulti(ctx, []string{"primary:2947", "backup:2947"})
+	multi.OnSourceChange(func(ev gpsd.SourceEvent) {
+		log.Printf("gps source: %s -> %s (%s)", ev.Previous, ev.Address, ev.Reason)
+	})
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](multi.Session(), 16)
+	go multi.Run(ctx, "json")
+
+The addresses are given in priority order. An endpoint is healthy while it is connected,
+has sent a TPV report within StaleAfter and that report has a 2D or 3D fix. The active
+source is the first healthy endpoint: when it goes silent or loses its fix, the
+MultiSession fails over to the next healthy one, and fails back once an endpoint of
+higher priority recovers. While no endpoint is healthy, the active source is kept.
+
+Every endpoint is a Session of its own, reconnecting following its ReconnectPolicy.
+*/
+type MultiSession struct {
+	// StaleAfter is the time without TPV reports after which an endpoint is considered silent,
+	// DefaultStaleAfter by default. It must be set before Run.
+	StaleAfter time.Duration
+
+	output    *Session
+	endpoints []*endpoint
+
+	// switchMu serialises source selections, so that events are emitted in order.
+	switchMu sync.Mutex
+	mu       sync.Mutex
+	active   int
+	handlers []func(SourceEvent)
+}
+
+// endpoint is a gpsd followed by a MultiSession.
+type endpoint struct {
+	session *Session
+
+	// Guarded by MultiSession.mu.
+	connected bool
+	lastTPV   time.Time
+	fix       bool
+}
+
+// DialMulti connects to the gpsd instances at the addresses, given in priority order.
+// It succeeds once at least one of them is connected: the others are connected by Run
+// following the ReconnectPolicy. The options configure the session of every endpoint
+// and the session returned by MultiSession.Session.
+func DialMulti(ctx context.Context, addresses []string, opts ...Option) (*MultiSession, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("gpsd: no address to dial")
+	}
+
+	m := &MultiSession{
+		StaleAfter: DefaultStaleAfter,
+		output:     newSession("multi", opts...),
+		active:     -1,
+	}
+
+	var err error
+	connected := 0
+	for _, address := range addresses {
+		e := &endpoint{session: newSession(address, opts...)}
+		m.endpoints = append(m.endpoints, e)
+		m.follow(e)
+
+		e.session.setState(StateConnecting, 0, nil)
+		if derr := e.session.dial(ctx); derr != nil {
+			e.session.setState(StateDisconnected, 0, derr)
+			err = derr
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		e.session.setState(StateConnected, 0, nil)
+		connected++
+	}
+
+	if connected == 0 {
+		m.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("gpsd: failed to connect to any of %v: %w", addresses, err)
+	}
+	return m, nil
+}
+
+// follow tracks the health of the endpoint and relays the lines it reads while it is active.
+func (m *MultiSession) follow(e *endpoint) {
+	e.session.OnStateChange(func(ev StateEvent) {
+		m.mu.Lock()
+		e.connected = ev.State == StateConnected
+		m.mu.Unlock()
+		if ev.State != StateConnected {
+			m.evaluate(time.Now())
+		}
+	})
+	SubscribeFunc(e.session, func(r *TPVReport) {
+		now := time.Now()
+		m.mu.Lock()
+		e.lastTPV = now
+		e.fix = r.Mode >= Mode2D
+		m.mu.Unlock()
+		m.evaluate(now)
+	})
+	e.session.onLine(func(line string) {
+		if m.isActive(e) {
+			m.output.handleLine(line)
+		}
+	})
+}
+
+// Session returns the session relaying the reports and sentences of the active source,
+// to subscribe to them. It is not connected to gpsd, so commands sent through it
+// fail with ErrNotConnected: use ActiveSession or Sessions instead.
+func (m *MultiSession) Session() *Session {
+	return m.output
+}
+
+// Sessions returns the sessions of the endpoints in priority order.
+func (m *MultiSession) Sessions() []*Session {
+	sessions := make([]*Session, len(m.endpoints))
+	for i, e := range m.endpoints {
+		sessions[i] = e.session
+	}
+	return sessions
+}
+
+// Active returns the address of the active source, empty until an endpoint has had a fix.
+func (m *MultiSession) Active() string {
+	if s := m.ActiveSession(); s != nil {
+		return s.address
+	}
+	return ""
+}
+
+// ActiveSession returns the session of the active source, nil until an endpoint has had a fix.
+func (m *MultiSession) ActiveSession() *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.active < 0 {
+		return nil
+	}
+	return m.endpoints[m.active].session
+}
+
+// OnSourceChange registers f to be called every time the active source changes.
+// f is called synchronously from a session goroutine, so it must not block.
+func (m *MultiSession) OnSourceChange(f func(SourceEvent)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, f)
+}
+
+// Run streams the format from every endpoint, see Session.RunContext, until ctx is done,
+// the MultiSession is closed or every endpoint has stopped. Supported formats are
+// "json", "nmea" and "json+nmea". It returns ctx.Err() on cancellation, ErrClosed
+// after Close, or the error of the last endpoint that stopped.
+func (m *MultiSession) Run(ctx context.Context, format string) error {
+	switch format {
+	case formatJSON, formatNMEA, formatMixed:
+	default:
+		return fmt.Errorf("gpsd: unsupported format %q", format)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	staleAfter := m.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = DefaultStaleAfter
+	}
+	m.mu.Lock()
+	m.StaleAfter = staleAfter
+	m.mu.Unlock()
+
+	errs := make(chan error, len(m.endpoints))
+	for _, e := range m.endpoints {
+		go func(s *Session) {
+			errs <- s.RunContext(ctx, format)
+		}(e.session)
+	}
+
+	interval := staleAfter / 4
+	if interval < minHealthCheckInterval {
+		interval = minHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var err error
+	for running := len(m.endpoints); running > 0; {
+		select {
+		case <-m.output.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		case now := <-ticker.C:
+			m.evaluate(now)
+			continue
+		case err = <-errs:
+			running--
+			continue
+		}
+		// Wait for the endpoints to stop.
+		for ; running > 0; running-- {
+			<-errs
+		}
+		return m.output.runErr(ctx)
+	}
+	return err
+}
+
+// Close closes the connections to every gpsd and stops Run.
+func (m *MultiSession) Close() error {
+	err := m.output.Close()
+	for _, e := range m.endpoints {
+		if cerr := e.session.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (m *MultiSession) isActive(e *endpoint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active >= 0 && m.endpoints[m.active] == e
+}
+
+// healthy reports whether the endpoint is connected and has a recent fix. Called with m.mu held.
+func (m *MultiSession) healthy(e *endpoint, now time.Time) bool {
+	return e.connected && e.fix && now.Sub(e.lastTPV) < m.StaleAfter
+}
+
+// evaluate makes the first healthy endpoint the active source.
+func (m *MultiSession) evaluate(now time.Time) {
+	m.switchMu.Lock()
+	defer m.switchMu.Unlock()
+	// Endpoints being closed are not failures.
+	if m.output.ctx.Err() != nil {
+		return
+	}
+
+	m.mu.Lock()
+	best := -1
+	for i, e := range m.endpoints {
+		if m.healthy(e, now) {
+			best = i
+			break
+		}
+	}
+	if best < 0 || best == m.active {
+		m.mu.Unlock()
+		return
+	}
+
+	ev := SourceEvent{Address: m.endpoints[best].session.address, Reason: SwitchInitial}
+	if m.active >= 0 {
+		prev := m.endpoints[m.active]
+		ev.Previous = prev.session.address
+		switch {
+		case !prev.connected || now.Sub(prev.lastTPV) >= m.StaleAfter:
+			ev.Reason = SwitchSilent
+		case !prev.fix:
+			ev.Reason = SwitchLostFix
+		default:
+			ev.Reason = SwitchFailback
+		}
+	}
+	m.active = best
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	if ev.Reason == SwitchInitial {
+		m.output.logger.Debug("gpsd: source selected", "address", ev.Address)
+	} else {
+		m.output.logger.Warn("gpsd: failed over to another source", "address", ev.Address, "previous", ev.Previous, "reason", ev.Reason)
+	}
+	for _, f := range handlers {
+		f(ev)
+	}
+}
@@ -0,0 +1,178 @@
+package gpsd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vpakhuchyi/go-gpsd"
+	"github.com/vpakhuchyi/go-gpsd/gpsdtest"
+)
+
+const (
+	fixTPV   = `{"class":"TPV","device":"/dev/gpsdtest","mode":3,"lat":50.45,"lon":30.52}`
+	noFixTPV = `{"class":"TPV","device":"/dev/gpsdtest","mode":1}`
+)
+
+// dialMulti starts a fake gpsd per address and runs a MultiSession following them
+// until the end of the test. The returned channel receives its source events.
+func dialMulti(t *testing.T, staleAfter time.Duration, n int) ([]*gpsdtest.Server, *gpsd.MultiSession, <-chan gpsd.SourceEvent) {
+	t.Helper()
+	var (
+		servers   []*gpsdtest.Server
+		addresses []string
+	)
+	for i := 0; i < n; i++ {
+		srv := gpsdtest.NewServer()
+		t.Cleanup(srv.Close)
+		servers = append(servers, srv)
+		addresses = append(addresses, srv.Addr)
+	}
+
+	multi, err := gpsd.DialMulti(context.Background(), addresses)
+	if err != nil {
+		t.Fatalf("DialMulti() error = %v", err)
+	}
+	t.Cleanup(func() { _ = multi.Close() })
+	multi.StaleAfter = staleAfter
+	events := make(chan gpsd.SourceEvent, 16)
+	multi.OnSourceChange(func(ev gpsd.SourceEvent) { events <- ev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = multi.Run(ctx, "json")
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	for _, srv := range servers {
+		if err := srv.WaitForCommand(timeout, "WATCH"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return servers, multi, events
+}
+
+// feed sends the TPV report every interval until stop is closed or the test ends.
+func feed(t *testing.T, srv *gpsdtest.Server, tpv string, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			srv.Send(tpv)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	var stopped bool
+	stop = func() {
+		if !stopped {
+			stopped = true
+			close(done)
+		}
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestMultiSessionFailover(t *testing.T) {
+	servers, multi, events := dialMulti(t, 200*time.Millisecond, 2)
+	primary, backup := servers[0], servers[1]
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](multi.Session(), 16)
+
+	expect := func(want gpsd.SourceEvent) {
+		t.Helper()
+		if ev := receive(t, events); ev != want {
+			t.Fatalf("source event = %+v, want %+v", ev, want)
+		}
+		if active := multi.Active(); active != want.Address {
+			t.Fatalf("Active() = %s, want %s", active, want.Address)
+		}
+	}
+
+	stopPrimary := feed(t, primary, fixTPV, 20*time.Millisecond)
+	expect(gpsd.SourceEvent{Address: primary.Addr, Reason: gpsd.SwitchInitial})
+	if r := receive(t, tpv); r.Mode != gpsd.Mode3D {
+		t.Errorf("relayed TPV = %+v", r)
+	}
+
+	// The backup has a fix but it is not used while the primary is healthy.
+	feed(t, backup, fixTPV, 20*time.Millisecond)
+	stopPrimary()
+	expect(gpsd.SourceEvent{Address: backup.Addr, Previous: primary.Addr, Reason: gpsd.SwitchSilent})
+
+	primary.Send(fixTPV)
+	expect(gpsd.SourceEvent{Address: primary.Addr, Previous: backup.Addr, Reason: gpsd.SwitchFailback})
+
+	primary.Send(noFixTPV)
+	expect(gpsd.SourceEvent{Address: backup.Addr, Previous: primary.Addr, Reason: gpsd.SwitchLostFix})
+
+	// From now on, only the reports of the backup are relayed until the failback.
+	for len(tpv) > 0 {
+		<-tpv
+	}
+	primary.Send(`{"class":"TPV","device":"/dev/gpsdtest","mode":2,"lat":1,"lon":2}`)
+	expect(gpsd.SourceEvent{Address: primary.Addr, Previous: backup.Addr, Reason: gpsd.SwitchFailback})
+	for r := receive(t, tpv); r.Lat != 1; r = receive(t, tpv) {
+		if r.Mode != gpsd.Mode3D {
+			t.Errorf("relayed TPV = %+v, want the ones of the backup until the failback", r)
+		}
+	}
+}
+
+func TestMultiSessionNoSource(t *testing.T) {
+	servers, multi, events := dialMulti(t, 100*time.Millisecond, 2)
+
+	// No endpoint has a fix, so no source is selected.
+	servers[0].Send(noFixTPV)
+	servers[1].Send(noFixTPV)
+	select {
+	case ev := <-events:
+		t.Fatalf("source event = %+v, want none", ev)
+	case <-time.After(300 * time.Millisecond):
+	}
+	if active := multi.Active(); active != "" {
+		t.Errorf("Active() = %s, want none", active)
+	}
+
+	// The active source is kept while no endpoint is healthy.
+	servers[1].Send(fixTPV)
+	if ev := receive(t, events); ev.Address != servers[1].Addr || ev.Reason != gpsd.SwitchInitial {
+		t.Fatalf("source event = %+v, want the initial selection of the backup", ev)
+	}
+	servers[1].Send(noFixTPV)
+	select {
+	case ev := <-events:
+		t.Fatalf("source event = %+v, want none", ev)
+	case <-time.After(300 * time.Millisecond):
+	}
+	if active := multi.Active(); active != servers[1].Addr {
+		t.Errorf("Active() = %s, want %s", active, servers[1].Addr)
+	}
+}
+
+func TestMultiSessionTinyStaleAfter(t *testing.T) {
+	srv := gpsdtest.NewServer()
+	defer srv.Close()
+	multi, err := gpsd.DialMulti(context.Background(), []string{srv.Addr})
+	if err != nil {
+		t.Fatalf("DialMulti() error = %v", err)
+	}
+	defer multi.Close()
+
+	// A quarter of StaleAfter rounds down to zero, which must not stop Run.
+	multi.StaleAfter = 3
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := multi.Run(ctx, "json"); err != context.DeadlineExceeded {
+		t.Errorf("Run() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
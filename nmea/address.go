@@ -0,0 +1,83 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// EncapsulationStart is the first character of an encapsulated sentence, e.g. !AIVDM.
+	EncapsulationStart = '!'
+	// ProprietaryPrefix starts the address field of proprietary sentences, e.g. $PUBX or $PGRMZ.
+	ProprietaryPrefix = "P"
+)
+
+/*
+Address is the address field of a sentence split into its parts
+
+	$GPGGA   -> Talker "GP", Type "GGA"
+	!AIVDM   -> Talker "AI", Type "VDM", Encapsulated
+	$PGRMZ   -> Talker "P", Manufacturer "GRM", Type "GRMZ", Proprietary
+	$PUBX,00 -> Talker "P", Manufacturer "UBX", Type "UBX", Proprietary
+*/
+type Address struct {
+	// Talker ID, e.g. "GP", "GN" or "AI". "P" for proprietary sentences.
+	Talker string
+	// Sentence type, e.g. "GGA". For proprietary sentences it is the address without the "P" prefix.
+	Type string
+	// Manufacturer mnemonic code of proprietary sentences, e.g. "GRM" or "UBX".
+	Manufacturer string
+	// Proprietary is set for sentences defined by a manufacturer rather than by the standard.
+	Proprietary bool
+	// Encapsulated is set for sentences starting with '!', which carry an encoded payload such as AIS.
+	Encapsulated bool
+}
+
+// String returns the address as sent, e.g. "GPGGA" or "PGRMZ".
+func (a Address) String() string {
+	if a.Proprietary {
+		return ProprietaryPrefix + a.Type
+	}
+	return a.Talker + a.Type
+}
+
+// ParseAddress tokenizes the address field of a sentence without validating the rest of it,
+// so that sentences can be routed cheaply before being parsed.
+func ParseAddress(line string) (Address, error) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || (line[0] != SentenceStart && line[0] != EncapsulationStart) {
+		return Address{}, fmt.Errorf("%w: %q", ErrInvalidSentence, line)
+	}
+
+	address := line[1:]
+	if i := strings.IndexAny(address, FieldSeparator+string(ChecksumSeparator)); i >= 0 {
+		address = address[:i]
+	}
+	for i := 0; i < len(address); i++ {
+		if c := address[i]; (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return Address{}, fmt.Errorf("%w: bad address field %q", ErrInvalidSentence, address)
+		}
+	}
+
+	encapsulated := line[0] == EncapsulationStart
+	switch {
+	case strings.HasPrefix(address, ProprietaryPrefix) && !encapsulated:
+		if len(address) < 4 {
+			return Address{}, fmt.Errorf("%w: bad proprietary address field %q", ErrInvalidSentence, address)
+		}
+		return Address{
+			Talker:       ProprietaryPrefix,
+			Type:         address[1:],
+			Manufacturer: address[1:4],
+			Proprietary:  true,
+		}, nil
+	case len(address) == 5:
+		return Address{
+			Talker:       address[:2],
+			Type:         address[2:],
+			Encapsulated: encapsulated,
+		}, nil
+	default:
+		return Address{}, fmt.Errorf("%w: bad address field %q", ErrInvalidSentence, address)
+	}
+}
@@ -0,0 +1,47 @@
+package nmea
+
+// Sentence types of encapsulated AIS messages.
+const (
+	TypeVDM = "VDM"
+	TypeVDO = "VDO"
+)
+
+/*
+VDMVDO is an encapsulated AIS message: VDM for messages received from other vessels
+and VDO for the own vessel
+
+Long messages are split into several fragments sharing the same MessageID.
+The payload is left in its 6-bit armored form.
+
+example:
+
+	!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5C
+*/
+type VDMVDO struct {
+	BaseSentence
+	// Total number of fragments of the message.
+	NumFragments int64
+	// Number of this fragment, starting with 1.
+	FragmentNumber int64
+	// Sequential message ID shared by the fragments of a multi-sentence message, empty for single ones.
+	MessageID string
+	// Radio channel, "A" or "B".
+	Channel string
+	// Armored payload.
+	Payload string
+	// Number of fill bits added to the payload to complete the last 6-bit character.
+	FillBits int64
+}
+
+func newVDMVDO(base BaseSentence) (VDMVDO, error) {
+	p := newParser(base)
+	return VDMVDO{
+		BaseSentence:   base,
+		NumFragments:   p.Int64(0, "number of fragments"),
+		FragmentNumber: p.Int64(1, "fragment number"),
+		MessageID:      p.String(2),
+		Channel:        p.String(3),
+		Payload:        p.String(4),
+		FillBits:       p.Int64(5, "fill bits"),
+	}, p.Err()
+}
@@ -0,0 +1,64 @@
+package nmea
+
+// Sentence types of heading.
+const (
+	TypeHDT = "HDT"
+	TypeTHS = "THS"
+)
+
+// Mode indicators of THS sentences.
+const (
+	THSAutonomous = "A"
+	THSEstimated  = "E"
+	THSManual     = "M"
+	THSSimulator  = "S"
+	THSInvalid    = "V"
+)
+
+/*
+HDT is a heading, true sentence
+
+example:
+
+	$GPHDT,274.07,T*03
+*/
+type HDT struct {
+	BaseSentence
+	// Heading in degrees true.
+	Heading float64
+	// True is set if the heading is relative to true north.
+	True bool
+}
+
+func newHDT(base BaseSentence) (HDT, error) {
+	p := newParser(base)
+	return HDT{
+		BaseSentence: base,
+		Heading:      p.Float64(0, "heading"),
+		True:         p.Enum(1, "true", "T") == "T",
+	}, p.Err()
+}
+
+/*
+THS is a true heading and status sentence
+
+example:
+
+	$INTHS,123.456,A*20
+*/
+type THS struct {
+	BaseSentence
+	// Heading in degrees true.
+	Heading float64
+	// Mode indicator, see the THS constants.
+	Status string
+}
+
+func newTHS(base BaseSentence) (THS, error) {
+	p := newParser(base)
+	return THS{
+		BaseSentence: base,
+		Heading:      p.Float64(0, "heading"),
+		Status:       p.Enum(1, "status", THSAutonomous, THSEstimated, THSManual, THSSimulator, THSInvalid),
+	}, p.Err()
+}
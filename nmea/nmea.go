@@ -0,0 +1,270 @@
+/*
+Package nmea parses NMEA 0183 sentences as relayed by gpsd in nmea watch mode.
+
+Parse validates the checksum of a sentence and decodes the supported sentence types
+(GGA, RMC, GSA, GSV, VTG, GLL, ZDA, GST, GBS, HDT and THS) into typed structs.
+Encapsulated AIS sentences (VDM and VDO) are split into their fragment fields,
+the payload is left armored. Other sentences, including proprietary ones such as
+$PUBX or $PGRMZ, are returned as BaseSentence with their raw fields.
+*/
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SentenceStart is the first character of a parametric sentence.
+	SentenceStart = '$'
+	// ChecksumSeparator separates the data fields from the checksum.
+	ChecksumSeparator = '*'
+	// FieldSeparator separates the fields of a sentence.
+	FieldSeparator = ","
+)
+
+var (
+	// ErrInvalidSentence is returned for lines that are not NMEA sentences.
+	ErrInvalidSentence = errors.New("nmea: invalid sentence")
+	// ErrChecksum is returned when the checksum of a sentence is missing or does not match.
+	ErrChecksum = errors.New("nmea: checksum mismatch")
+)
+
+// Sentence is implemented by BaseSentence and by every typed sentence embedding it.
+type Sentence interface {
+	fmt.Stringer
+	// TalkerID returns the talker of the sentence, e.g. "GP" or "GN".
+	TalkerID() string
+	// DataType returns the type of the sentence, e.g. "GGA".
+	DataType() string
+}
+
+// BaseSentence contains the information common to all sentences.
+type BaseSentence struct {
+	// Address of the sentence: its talker ID and type.
+	Address
+	// Fields following the address field, without the checksum.
+	Fields []string
+	// Checksum as sent, in upper-case hex.
+	Checksum string
+	// Raw sentence without the line terminator.
+	Raw string
+}
+
+// TalkerID implements Sentence interface.
+func (b BaseSentence) TalkerID() string { return b.Talker }
+
+// DataType implements Sentence interface.
+func (b BaseSentence) DataType() string { return b.Type }
+
+// String implements fmt.Stringer interface and returns the raw sentence.
+func (b BaseSentence) String() string { return b.Raw }
+
+// Parse validates the sentence and decodes it into its typed representation.
+// Unsupported sentence types are returned as BaseSentence.
+func Parse(line string) (Sentence, error) {
+	base, err := ParseBase(line)
+	if err != nil {
+		return nil, err
+	}
+	if base.Proprietary {
+		return base, nil
+	}
+
+	switch base.Type {
+	case TypeGGA:
+		return newGGA(base)
+	case TypeRMC:
+		return newRMC(base)
+	case TypeGSA:
+		return newGSA(base)
+	case TypeGSV:
+		return newGSV(base)
+	case TypeVTG:
+		return newVTG(base)
+	case TypeGLL:
+		return newGLL(base)
+	case TypeZDA:
+		return newZDA(base)
+	case TypeGST:
+		return newGST(base)
+	case TypeGBS:
+		return newGBS(base)
+	case TypeHDT:
+		return newHDT(base)
+	case TypeTHS:
+		return newTHS(base)
+	case TypeVDM, TypeVDO:
+		return newVDMVDO(base)
+	default:
+		return base, nil
+	}
+}
+
+// ParseBase validates the checksum of the sentence and splits it into fields without decoding them.
+func ParseBase(line string) (BaseSentence, error) {
+	raw := strings.TrimSpace(line)
+	address, err := ParseAddress(raw)
+	if err != nil {
+		return BaseSentence{}, err
+	}
+
+	sum := strings.IndexByte(raw, ChecksumSeparator)
+	if sum < 0 {
+		return BaseSentence{}, fmt.Errorf("%w: missing checksum in %q", ErrChecksum, raw)
+	}
+	body, checksum := raw[1:sum], strings.ToUpper(raw[sum+1:])
+	if want := Checksum(body); checksum != want {
+		return BaseSentence{}, fmt.Errorf("%w: got %s, want %s in %q", ErrChecksum, checksum, want, raw)
+	}
+
+	fields := strings.Split(body, FieldSeparator)
+
+	return BaseSentence{
+		Address:  address,
+		Fields:   fields[1:],
+		Checksum: checksum,
+		Raw:      raw,
+	}, nil
+}
+
+// Checksum returns the checksum of the sentence body (the part between '$' and '*') in upper-case hex.
+func Checksum(body string) string {
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	return fmt.Sprintf("%02X", sum)
+}
+
+// parser decodes the fields of a sentence and remembers the first error.
+type parser struct {
+	BaseSentence
+	err error
+}
+
+func newParser(base BaseSentence) *parser {
+	return &parser{BaseSentence: base}
+}
+
+// Err returns the first error encountered while parsing the fields.
+func (p *parser) Err() error {
+	return p.err
+}
+
+func (p *parser) fail(i int, name, value string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("%w: %s: invalid %s %q in field %d", ErrInvalidSentence, p.Type, name, value, i+1)
+	}
+}
+
+// String returns the field i or an empty string if it is absent.
+func (p *parser) String(i int) string {
+	if i >= len(p.Fields) {
+		return ""
+	}
+	return p.Fields[i]
+}
+
+// Float64 returns the field i as a number, zero if it is empty.
+func (p *parser) Float64(i int, name string) float64 {
+	s := p.String(i)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.fail(i, name, s)
+	}
+	return v
+}
+
+// Int64 returns the field i as an integer, zero if it is empty.
+func (p *parser) Int64(i int, name string) int64 {
+	s := p.String(i)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		p.fail(i, name, s)
+	}
+	return v
+}
+
+// Enum returns the field i if it is empty or one of the allowed values.
+func (p *parser) Enum(i int, name string, allowed ...string) string {
+	s := p.String(i)
+	if s == "" {
+		return s
+	}
+	for _, a := range allowed {
+		if s == a {
+			return s
+		}
+	}
+	p.fail(i, name, s)
+	return s
+}
+
+// LatLon returns the coordinate in ddmm.mmmm (or dddmm.mmmm) format at field i with the hemisphere
+// at field i+1 as signed decimal degrees.
+func (p *parser) LatLon(i int, name string) float64 {
+	s, hemisphere := p.String(i), p.String(i+1)
+	if s == "" {
+		return 0
+	}
+
+	dot := strings.IndexByte(s, '.')
+	if dot < 0 {
+		dot = len(s)
+	}
+	if dot < 2 || !allDigits(s[:dot]) || (dot < len(s) && !allDigits(s[dot+1:])) {
+		p.fail(i, name, s)
+		return 0
+	}
+	deg, err1 := strconv.ParseFloat(s[:dot-2], 64)
+	minutes, err2 := strconv.ParseFloat(s[dot-2:], 64)
+	if err1 != nil || err2 != nil || minutes >= 60 {
+		p.fail(i, name, s)
+		return 0
+	}
+
+	v := deg + minutes/60
+	switch hemisphere {
+	case North, East:
+	case South, West:
+		v = -v
+	default:
+		p.fail(i+1, name+" hemisphere", hemisphere)
+	}
+	return v
+}
+
+// Time returns the hhmmss.ss time of day at field i.
+func (p *parser) Time(i int, name string) Time {
+	s := p.String(i)
+	if s == "" {
+		return Time{}
+	}
+	t, err := ParseTime(s)
+	if err != nil {
+		p.fail(i, name, s)
+	}
+	return t
+}
+
+// Date returns the ddmmyy date at field i.
+func (p *parser) Date(i int, name string) Date {
+	s := p.String(i)
+	if s == "" {
+		return Date{}
+	}
+	d, err := ParseDate(s)
+	if err != nil {
+		p.fail(i, name, s)
+	}
+	return d
+}
@@ -0,0 +1,436 @@
+package nmea
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// ddm converts degrees and decimal minutes like the parser does.
+func ddm(deg, minutes float64) float64 {
+	return deg + minutes/60
+}
+
+func TestParseGGA(t *testing.T) {
+	tests := []struct {
+		line string
+		want GGA
+	}{
+		{
+			line: "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47",
+			want: GGA{
+				Time:          Time{Valid: true, Hour: 12, Minute: 35, Second: 19},
+				Latitude:      ddm(48, 7.038),
+				Longitude:     ddm(11, 31),
+				Quality:       QualityGPS,
+				NumSatellites: 8,
+				HDOP:          0.9,
+				Altitude:      545.4,
+				Separation:    46.9,
+			},
+		},
+		{
+			line: "$GNGGA,092725.00,4717.11399,S,00833.91590,W,4,08,1.01,499.6,M,48.0,M,1.5,0031*67",
+			want: GGA{
+				Time:          Time{Valid: true, Hour: 9, Minute: 27, Second: 25},
+				Latitude:      -ddm(47, 17.11399),
+				Longitude:     -ddm(8, 33.91590),
+				Quality:       QualityRTKFixed,
+				NumSatellites: 8,
+				HDOP:          1.01,
+				Altitude:      499.6,
+				Separation:    48,
+				DGPSAge:       1.5,
+				DGPSID:        "0031",
+			},
+		},
+		{
+			// No fix yet: every field but the quality is empty.
+			line: "$GPGGA,,,,,,0,00,99.99,,,,,,*48",
+			want: GGA{HDOP: 99.99},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			s, err := Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, ok := s.(GGA)
+			if !ok {
+				t.Fatalf("Parse() = %T, want GGA", s)
+			}
+			got.BaseSentence = BaseSentence{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRMC(t *testing.T) {
+	tests := []struct {
+		line string
+		want RMC
+	}{
+		{
+			line: "$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A",
+			want: RMC{
+				Time:      Time{Valid: true, Hour: 12, Minute: 35, Second: 19},
+				Status:    StatusValid,
+				Latitude:  ddm(48, 7.038),
+				Longitude: ddm(11, 31),
+				Speed:     22.4,
+				Course:    84.4,
+				Date:      Date{Valid: true, Day: 23, Month: 3, Year: 1994},
+				Variation: -3.1,
+			},
+		},
+		{
+			// NMEA 4.1 with the mode and navigational status.
+			line: "$GNRMC,083559.00,A,4717.11437,N,00833.91522,E,0.004,77.52,091202,,,A,V*33",
+			want: RMC{
+				Time:      Time{Valid: true, Hour: 8, Minute: 35, Second: 59},
+				Status:    StatusValid,
+				Latitude:  ddm(47, 17.11437),
+				Longitude: ddm(8, 33.91522),
+				Speed:     0.004,
+				Course:    77.52,
+				Date:      Date{Valid: true, Day: 9, Month: 12, Year: 2002},
+				Mode:      "A",
+				NavStatus: "V",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			s, err := Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, ok := s.(RMC)
+			if !ok {
+				t.Fatalf("Parse() = %T, want RMC", s)
+			}
+			got.BaseSentence = BaseSentence{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGSA(t *testing.T) {
+	tests := []struct {
+		line string
+		want GSA
+	}{
+		{
+			line: "$GPGSA,A,3,04,05,,09,12,,,24,,,,,2.5,1.3,2.1*39",
+			want: GSA{
+				Mode:    "A",
+				FixType: Fix3D,
+				SV:      []string{"04", "05", "09", "12", "24"},
+				PDOP:    2.5,
+				HDOP:    1.3,
+				VDOP:    2.1,
+			},
+		},
+		{
+			// NMEA 4.1 with the GNSS system ID.
+			line: "$GNGSA,A,3,80,71,73,79,69,,,,,,,,1.83,1.09,1.47,2*09",
+			want: GSA{
+				Mode:     "A",
+				FixType:  Fix3D,
+				SV:       []string{"80", "71", "73", "79", "69"},
+				PDOP:     1.83,
+				HDOP:     1.09,
+				VDOP:     1.47,
+				SystemID: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			s, err := Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, ok := s.(GSA)
+			if !ok {
+				t.Fatalf("Parse() = %T, want GSA", s)
+			}
+			got.BaseSentence = BaseSentence{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGSV(t *testing.T) {
+	tests := []struct {
+		line string
+		want GSV
+	}{
+		{
+			line: "$GPGSV,3,1,11,03,03,111,00,04,15,270,00,06,01,010,00,13,06,292,00*74",
+			want: GSV{
+				TotalMessages:   3,
+				MessageNumber:   1,
+				NumberSVsInView: 11,
+				Info: []GSVInfo{
+					{SVPRNNumber: 3, Elevation: 3, Azimuth: 111},
+					{SVPRNNumber: 4, Elevation: 15, Azimuth: 270},
+					{SVPRNNumber: 6, Elevation: 1, Azimuth: 10},
+					{SVPRNNumber: 13, Elevation: 6, Azimuth: 292},
+				},
+			},
+		},
+		{
+			// Less than four satellites, followed by the NMEA 4.1 signal ID.
+			line: "$GPGSV,1,1,03,12,70,266,46,25,43,151,44,29,25,076,40,1*5F",
+			want: GSV{
+				TotalMessages:   1,
+				MessageNumber:   1,
+				NumberSVsInView: 3,
+				Info: []GSVInfo{
+					{SVPRNNumber: 12, Elevation: 70, Azimuth: 266, SNR: 46},
+					{SVPRNNumber: 25, Elevation: 43, Azimuth: 151, SNR: 44},
+					{SVPRNNumber: 29, Elevation: 25, Azimuth: 76, SNR: 40},
+				},
+				SignalID: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			s, err := Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, ok := s.(GSV)
+			if !ok {
+				t.Fatalf("Parse() = %T, want GSV", s)
+			}
+			got.BaseSentence = BaseSentence{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVTG(t *testing.T) {
+	tests := []struct {
+		line string
+		want VTG
+	}{
+		{
+			line: "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48",
+			want: VTG{TrueTrack: 54.7, MagneticTrack: 34.4, GroundSpeedKnots: 5.5, GroundSpeedKPH: 10.2},
+		},
+		{
+			line: "$GPVTG,77.52,T,,M,0.004,N,0.008,K,A*06",
+			want: VTG{TrueTrack: 77.52, GroundSpeedKnots: 0.004, GroundSpeedKPH: 0.008, Mode: "A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			s, err := Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, ok := s.(VTG)
+			if !ok {
+				t.Fatalf("Parse() = %T, want VTG", s)
+			}
+			got.BaseSentence = BaseSentence{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGST(t *testing.T) {
+	s, err := Parse("$GPGST,024603.00,3.2,6.6,4.7,47.3,5.8,5.6,22.0*58")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got, ok := s.(GST)
+	if !ok {
+		t.Fatalf("Parse() = %T, want GST", s)
+	}
+	got.BaseSentence = BaseSentence{}
+	want := GST{
+		Time:        Time{Valid: true, Hour: 2, Minute: 46, Second: 3},
+		RMS:         3.2,
+		Major:       6.6,
+		Minor:       4.7,
+		Orientation: 47.3,
+		LatError:    5.8,
+		LonError:    5.6,
+		AltError:    22,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantErr error
+	}{
+		{name: "valid", line: "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48"},
+		{name: "lower-case hex", line: "$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6a"},
+		{name: "line terminator", line: "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48\r\n"},
+		{name: "mismatch", line: "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*49", wantErr: ErrChecksum},
+		{name: "missing", line: "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K", wantErr: ErrChecksum},
+		{name: "corrupted field", line: "$GPVTG,054.7,T,034.4,M,005.6,N,010.2,K*48", wantErr: ErrChecksum},
+		{name: "not a sentence", line: `{"class":"TPV"}`, wantErr: ErrInvalidSentence},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.line)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseInvalidFields(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "signed latitude", line: "$GPGGA,123519,-4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*6A"},
+		{name: "signed minute", line: "$GPRMC,12-519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*74"},
+		{name: "bad hemisphere", line: "$GPGGA,123519,4807.038,X,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*51"},
+		{name: "bad status", line: "$GPRMC,123519,X,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*73"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.line); !errors.Is(err, ErrInvalidSentence) {
+				t.Errorf("Parse() error = %v, want %v", err, ErrInvalidSentence)
+			}
+		})
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Address
+		wantErr bool
+	}{
+		{line: "$GPGGA,123519,,,,,0,00,,,,,,,*66", want: Address{Talker: "GP", Type: "GGA"}},
+		{line: "$GNRMC,083559.00,A,,,,,,,091202,,,A,V*00", want: Address{Talker: "GN", Type: "RMC"}},
+		{line: "$BDGSV,1,1,02,201,45,120,40*6C", want: Address{Talker: "BD", Type: "GSV"}},
+		{line: "!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5C", want: Address{Talker: "AI", Type: "VDM", Encapsulated: true}},
+		{line: "$PGRMZ,246,f,3*1B", want: Address{Talker: "P", Type: "GRMZ", Manufacturer: "GRM", Proprietary: true}},
+		{line: "$PUBX,00,081350.00*5F", want: Address{Talker: "P", Type: "UBX", Manufacturer: "UBX", Proprietary: true}},
+		{line: "$GPGG,123519*00", wantErr: true},
+		{line: "$gpgga,123519*00", wantErr: true},
+		{line: "$PGR,1*00", wantErr: true},
+		{line: "GPGGA,123519*00", wantErr: true},
+		{line: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := ParseAddress(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAddress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProprietary(t *testing.T) {
+	s, err := Parse("$PGRMZ,246,f,3*1B")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	base, ok := s.(BaseSentence)
+	if !ok {
+		t.Fatalf("Parse() = %T, want BaseSentence", s)
+	}
+	if s.TalkerID() != "P" || s.DataType() != "GRMZ" || !reflect.DeepEqual(base.Fields, []string{"246", "f", "3"}) {
+		t.Errorf("Parse() = %+v", base)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Time
+		wantErr bool
+	}{
+		{in: "123519", want: Time{Valid: true, Hour: 12, Minute: 35, Second: 19}},
+		{in: "123519.5", want: Time{Valid: true, Hour: 12, Minute: 35, Second: 19, Millisecond: 500}},
+		{in: "092725.00", want: Time{Valid: true, Hour: 9, Minute: 27, Second: 25}},
+		{in: "235959.9996", want: Time{Valid: true, Hour: 23, Minute: 59, Second: 59, Millisecond: 999}},
+		{in: "235960.000", want: Time{Valid: true, Hour: 23, Minute: 59, Second: 60}},
+		{in: "12-519", wantErr: true},
+		{in: "+12519", wantErr: true},
+		{in: "123519.-5", wantErr: true},
+		{in: "240000", wantErr: true},
+		{in: "126000", wantErr: true},
+		{in: "12351", wantErr: true},
+		{in: "1235190", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTime(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: "230394", want: Date{Valid: true, Day: 23, Month: 3, Year: 1994}},
+		{in: "091202", want: Date{Valid: true, Day: 9, Month: 12, Year: 2002}},
+		{in: "-10394", wantErr: true},
+		{in: "23+394", wantErr: true},
+		{in: "320394", wantErr: true},
+		{in: "231394", wantErr: true},
+		{in: "2303945", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDate(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
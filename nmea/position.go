@@ -0,0 +1,242 @@
+package nmea
+
+import "time"
+
+// Sentence types of position, velocity and time.
+const (
+	TypeGGA = "GGA"
+	TypeGLL = "GLL"
+	TypeRMC = "RMC"
+	TypeVTG = "VTG"
+	TypeZDA = "ZDA"
+)
+
+// Status values of GLL and RMC sentences.
+const (
+	StatusValid   = "A"
+	StatusInvalid = "V"
+)
+
+// Fix qualities of GGA sentences.
+const (
+	QualityInvalid   = 0
+	QualityGPS       = 1
+	QualityDGPS      = 2
+	QualityPPS       = 3
+	QualityRTKFixed  = 4
+	QualityRTKFloat  = 5
+	QualityEstimated = 6
+	QualityManual    = 7
+	QualitySimulated = 8
+)
+
+/*
+GGA is a Global Positioning System fix data sentence
+
+example:
+
+	$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47
+*/
+type GGA struct {
+	BaseSentence
+	// UTC time of the fix.
+	Time Time
+	// Latitude in decimal degrees, negative in the southern hemisphere.
+	Latitude float64
+	// Longitude in decimal degrees, negative in the western hemisphere.
+	Longitude float64
+	// Fix quality, see the Quality constants.
+	Quality int64
+	// Number of satellites in use.
+	NumSatellites int64
+	// Horizontal dilution of precision.
+	HDOP float64
+	// Altitude above mean sea level in meters.
+	Altitude float64
+	// Geoidal separation in meters.
+	Separation float64
+	// Age of differential GPS data in seconds.
+	DGPSAge float64
+	// Differential reference station ID.
+	DGPSID string
+}
+
+func newGGA(base BaseSentence) (GGA, error) {
+	p := newParser(base)
+	return GGA{
+		BaseSentence:  base,
+		Time:          p.Time(0, "time"),
+		Latitude:      p.LatLon(1, "latitude"),
+		Longitude:     p.LatLon(3, "longitude"),
+		Quality:       p.Int64(5, "quality"),
+		NumSatellites: p.Int64(6, "number of satellites"),
+		HDOP:          p.Float64(7, "hdop"),
+		Altitude:      p.Float64(8, "altitude"),
+		Separation:    p.Float64(10, "separation"),
+		DGPSAge:       p.Float64(12, "dgps age"),
+		DGPSID:        p.String(13),
+	}, p.Err()
+}
+
+/*
+GLL is a geographic position sentence
+
+example:
+
+	$GPGLL,4916.45,N,12311.12,W,225444,A,A*5C
+*/
+type GLL struct {
+	BaseSentence
+	// Latitude in decimal degrees, negative in the southern hemisphere.
+	Latitude float64
+	// Longitude in decimal degrees, negative in the western hemisphere.
+	Longitude float64
+	// UTC time of the position.
+	Time Time
+	// StatusValid or StatusInvalid.
+	Status string
+	// FAA mode indicator (NMEA 2.3 and later), empty if absent.
+	Mode string
+}
+
+func newGLL(base BaseSentence) (GLL, error) {
+	p := newParser(base)
+	return GLL{
+		BaseSentence: base,
+		Latitude:     p.LatLon(0, "latitude"),
+		Longitude:    p.LatLon(2, "longitude"),
+		Time:         p.Time(4, "time"),
+		Status:       p.Enum(5, "status", StatusValid, StatusInvalid),
+		Mode:         p.String(6),
+	}, p.Err()
+}
+
+/*
+RMC is a recommended minimum specific GNSS data sentence
+
+example:
+
+	$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A
+*/
+type RMC struct {
+	BaseSentence
+	// UTC time of the fix.
+	Time Time
+	// StatusValid or StatusInvalid.
+	Status string
+	// Latitude in decimal degrees, negative in the southern hemisphere.
+	Latitude float64
+	// Longitude in decimal degrees, negative in the western hemisphere.
+	Longitude float64
+	// Speed over ground in knots.
+	Speed float64
+	// Course over ground in degrees true.
+	Course float64
+	// UTC date of the fix.
+	Date Date
+	// Magnetic variation in degrees, negative when westerly.
+	Variation float64
+	// FAA mode indicator (NMEA 2.3 and later), empty if absent.
+	Mode string
+	// Navigational status (NMEA 4.1 and later), empty if absent.
+	NavStatus string
+}
+
+// DateTime returns the date and time of the fix, or the zero time if either is absent.
+func (s RMC) DateTime() time.Time {
+	return DateTime(s.Date, s.Time)
+}
+
+func newRMC(base BaseSentence) (RMC, error) {
+	p := newParser(base)
+	s := RMC{
+		BaseSentence: base,
+		Time:         p.Time(0, "time"),
+		Status:       p.Enum(1, "status", StatusValid, StatusInvalid),
+		Latitude:     p.LatLon(2, "latitude"),
+		Longitude:    p.LatLon(4, "longitude"),
+		Speed:        p.Float64(6, "speed"),
+		Course:       p.Float64(7, "course"),
+		Date:         p.Date(8, "date"),
+		Variation:    p.Float64(9, "variation"),
+		Mode:         p.String(11),
+		NavStatus:    p.String(12),
+	}
+	if p.Enum(10, "variation direction", East, West) == West {
+		s.Variation = -s.Variation
+	}
+	return s, p.Err()
+}
+
+/*
+VTG is a course over ground and ground speed sentence
+
+example:
+
+	$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48
+*/
+type VTG struct {
+	BaseSentence
+	// Course over ground in degrees true.
+	TrueTrack float64
+	// Course over ground in degrees magnetic.
+	MagneticTrack float64
+	// Speed over ground in knots.
+	GroundSpeedKnots float64
+	// Speed over ground in kilometers per hour.
+	GroundSpeedKPH float64
+	// FAA mode indicator (NMEA 2.3 and later), empty if absent.
+	Mode string
+}
+
+func newVTG(base BaseSentence) (VTG, error) {
+	p := newParser(base)
+	return VTG{
+		BaseSentence:     base,
+		TrueTrack:        p.Float64(0, "true track"),
+		MagneticTrack:    p.Float64(2, "magnetic track"),
+		GroundSpeedKnots: p.Float64(4, "ground speed (knots)"),
+		GroundSpeedKPH:   p.Float64(6, "ground speed (km/h)"),
+		Mode:             p.String(8),
+	}, p.Err()
+}
+
+/*
+ZDA is a time and date sentence
+
+example:
+
+	$GPZDA,201530.00,04,07,2002,00,00*60
+*/
+type ZDA struct {
+	BaseSentence
+	// UTC time of day.
+	Time Time
+	// UTC day, 1-31.
+	Day int64
+	// UTC month, 1-12.
+	Month int64
+	// UTC year, four digits.
+	Year int64
+	// Local zone offset from UTC, hours and minutes.
+	OffsetHours   int64
+	OffsetMinutes int64
+}
+
+// DateTime returns the UTC date and time, or the zero time if either is absent.
+func (s ZDA) DateTime() time.Time {
+	return DateTime(Date{Valid: s.Year > 0, Day: int(s.Day), Month: int(s.Month), Year: int(s.Year)}, s.Time)
+}
+
+func newZDA(base BaseSentence) (ZDA, error) {
+	p := newParser(base)
+	return ZDA{
+		BaseSentence:  base,
+		Time:          p.Time(0, "time"),
+		Day:           p.Int64(1, "day"),
+		Month:         p.Int64(2, "month"),
+		Year:          p.Int64(3, "year"),
+		OffsetHours:   p.Int64(4, "offset hours"),
+		OffsetMinutes: p.Int64(5, "offset minutes"),
+	}, p.Err()
+}
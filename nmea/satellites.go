@@ -0,0 +1,203 @@
+package nmea
+
+// Sentence types of satellites and accuracy.
+const (
+	TypeGSA = "GSA"
+	TypeGSV = "GSV"
+	TypeGST = "GST"
+	TypeGBS = "GBS"
+)
+
+// Fix types of GSA sentences.
+const (
+	FixNone = 1
+	Fix2D   = 2
+	Fix3D   = 3
+)
+
+/*
+GSA is a GNSS DOP and active satellites sentence
+
+example:
+
+	$GPGSA,A,3,04,05,,09,12,,,24,,,,,2.5,1.3,2.1*39
+*/
+type GSA struct {
+	BaseSentence
+	// Selection mode: "M" for manual, "A" for automatic.
+	Mode string
+	// Fix type, see the Fix constants.
+	FixType int64
+	// PRNs of the satellites used in the solution, without empty slots.
+	SV []string
+	// Position, horizontal and vertical dilution of precision.
+	PDOP float64
+	HDOP float64
+	VDOP float64
+	// GNSS system ID (NMEA 4.1 and later), zero if absent.
+	SystemID int64
+}
+
+func newGSA(base BaseSentence) (GSA, error) {
+	p := newParser(base)
+	s := GSA{
+		BaseSentence: base,
+		Mode:         p.Enum(0, "selection mode", "A", "M"),
+		FixType:      p.Int64(1, "fix type"),
+		PDOP:         p.Float64(14, "pdop"),
+		HDOP:         p.Float64(15, "hdop"),
+		VDOP:         p.Float64(16, "vdop"),
+		SystemID:     p.Int64(17, "system id"),
+	}
+	for i := 2; i < 14; i++ {
+		if sv := p.String(i); sv != "" {
+			s.SV = append(s.SV, sv)
+		}
+	}
+	return s, p.Err()
+}
+
+/*
+GSV is a GNSS satellites in view sentence
+
+A full sky view is usually split across several GSV sentences, each carrying up to four satellites.
+
+example:
+
+	$GPGSV,3,1,11,03,03,111,00,04,15,270,00,06,01,010,00,13,06,292,00*74
+*/
+type GSV struct {
+	BaseSentence
+	// Total number of GSV sentences in this cycle.
+	TotalMessages int64
+	// Number of this sentence, starting with 1.
+	MessageNumber int64
+	// Total number of satellites in view.
+	NumberSVsInView int64
+	// Satellites carried by this sentence.
+	Info []GSVInfo
+	// Signal ID (NMEA 4.1 and later), zero if absent.
+	SignalID int64
+}
+
+// GSVInfo describes a single satellite in view.
+type GSVInfo struct {
+	// Satellite PRN.
+	SVPRNNumber int64
+	// Elevation in degrees, 90 maximum.
+	Elevation int64
+	// Azimuth in degrees from true north, 000 to 359.
+	Azimuth int64
+	// Signal to noise ratio in dB-Hz, 00-99, zero when not tracking.
+	SNR int64
+}
+
+func newGSV(base BaseSentence) (GSV, error) {
+	p := newParser(base)
+	s := GSV{
+		BaseSentence:    base,
+		TotalMessages:   p.Int64(0, "total messages"),
+		MessageNumber:   p.Int64(1, "message number"),
+		NumberSVsInView: p.Int64(2, "number of SVs in view"),
+	}
+
+	i := 3
+	for ; i+4 <= len(base.Fields); i += 4 {
+		s.Info = append(s.Info, GSVInfo{
+			SVPRNNumber: p.Int64(i, "SV prn number"),
+			Elevation:   p.Int64(i+1, "elevation"),
+			Azimuth:     p.Int64(i+2, "azimuth"),
+			SNR:         p.Int64(i+3, "SNR"),
+		})
+	}
+	// A single field left after the satellites is the signal ID.
+	if i == len(base.Fields)-1 {
+		s.SignalID = p.Int64(i, "signal id")
+	}
+	return s, p.Err()
+}
+
+/*
+GST is a GNSS pseudorange error statistics sentence
+
+example:
+
+	$GPGST,024603.00,3.2,6.6,4.7,47.3,5.8,5.6,22.0*58
+*/
+type GST struct {
+	BaseSentence
+	// UTC time of the associated GGA fix.
+	Time Time
+	// RMS value of the standard deviation of the range inputs to the navigation process.
+	RMS float64
+	// Standard deviation of semi-major axis of error ellipse in meters.
+	Major float64
+	// Standard deviation of semi-minor axis of error ellipse in meters.
+	Minor float64
+	// Orientation of semi-major axis of error ellipse in degrees from true north.
+	Orientation float64
+	// Standard deviation of latitude, longitude and altitude error in meters.
+	LatError float64
+	LonError float64
+	AltError float64
+}
+
+func newGST(base BaseSentence) (GST, error) {
+	p := newParser(base)
+	return GST{
+		BaseSentence: base,
+		Time:         p.Time(0, "time"),
+		RMS:          p.Float64(1, "rms"),
+		Major:        p.Float64(2, "major"),
+		Minor:        p.Float64(3, "minor"),
+		Orientation:  p.Float64(4, "orientation"),
+		LatError:     p.Float64(5, "latitude error"),
+		LonError:     p.Float64(6, "longitude error"),
+		AltError:     p.Float64(7, "altitude error"),
+	}, p.Err()
+}
+
+/*
+GBS is a GNSS satellite fault detection sentence
+
+example:
+
+	$GPGBS,235458.00,1.4,1.3,3.1,03,,-21.4,3.8,1,0*5A
+*/
+type GBS struct {
+	BaseSentence
+	// UTC time of the associated GGA or GNS fix.
+	Time Time
+	// Expected error in latitude, longitude and altitude in meters.
+	LatError float64
+	LonError float64
+	AltError float64
+	// ID of the most likely failed satellite.
+	FailedSV string
+	// Probability of missed detection for the most likely failed satellite.
+	Probability float64
+	// Estimate of bias in meters on the most likely failed satellite.
+	Bias float64
+	// Standard deviation of the bias estimate.
+	StdDev float64
+	// GNSS system and signal IDs (NMEA 4.1 and later), zero if absent.
+	SystemID int64
+	SignalID int64
+}
+
+func newGBS(base BaseSentence) (GBS, error) {
+	p := newParser(base)
+	return GBS{
+		BaseSentence: base,
+		Time:         p.Time(0, "time"),
+		LatError:     p.Float64(1, "latitude error"),
+		LonError:     p.Float64(2, "longitude error"),
+		AltError:     p.Float64(3, "altitude error"),
+		FailedSV:     p.String(4),
+		Probability:  p.Float64(5, "probability"),
+		Bias:         p.Float64(6, "bias"),
+		StdDev:       p.Float64(7, "standard deviation"),
+		SystemID:     p.Int64(8, "system id"),
+		SignalID:     p.Int64(9, "signal id"),
+	}, p.Err()
+}
@@ -0,0 +1,129 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Hemispheres and directions of coordinates.
+const (
+	North = "N"
+	South = "S"
+	East  = "E"
+	West  = "W"
+)
+
+// Time is a UTC time of day as reported in NMEA sentences.
+type Time struct {
+	// Valid is false if the field was empty.
+	Valid       bool
+	Hour        int
+	Minute      int
+	Second      int
+	Millisecond int
+}
+
+// ParseTime parses the hhmmss.ss time of day. Fractions of a second are truncated to milliseconds.
+func ParseTime(s string) (Time, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(whole) != 6 || !allDigits(whole) || !allDigits(frac) {
+		return Time{}, fmt.Errorf("nmea: invalid time %q", s)
+	}
+	h, m, sec := twoDigits(whole[0:2]), twoDigits(whole[2:4]), twoDigits(whole[4:6])
+	// 60 is a leap second.
+	if h > 23 || m > 59 || sec > 60 {
+		return Time{}, fmt.Errorf("nmea: invalid time %q", s)
+	}
+
+	// Truncating rather than rounding never carries into the seconds.
+	ms := 0
+	for i := 0; i < 3; i++ {
+		ms *= 10
+		if i < len(frac) {
+			ms += int(frac[i] - '0')
+		}
+	}
+
+	return Time{
+		Valid:       true,
+		Hour:        h,
+		Minute:      m,
+		Second:      sec,
+		Millisecond: ms,
+	}, nil
+}
+
+// Duration returns the time elapsed since midnight.
+func (t Time) Duration() time.Duration {
+	return time.Duration(t.Hour)*time.Hour +
+		time.Duration(t.Minute)*time.Minute +
+		time.Duration(t.Second)*time.Second +
+		time.Duration(t.Millisecond)*time.Millisecond
+}
+
+// String implements fmt.Stringer interface.
+func (t Time) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", t.Hour, t.Minute, t.Second, t.Millisecond)
+}
+
+// Date is a UTC date as reported in NMEA sentences.
+type Date struct {
+	// Valid is false if the field was empty.
+	Valid bool
+	Day   int
+	Month int
+	// Year is the full year, two-digit years are mapped to 1980-2079.
+	Year int
+}
+
+// ParseDate parses the ddmmyy date.
+func ParseDate(s string) (Date, error) {
+	if len(s) != 6 || !allDigits(s) {
+		return Date{}, fmt.Errorf("nmea: invalid date %q", s)
+	}
+	d, m, y := twoDigits(s[0:2]), twoDigits(s[2:4]), twoDigits(s[4:6])
+	if d < 1 || d > 31 || m < 1 || m > 12 {
+		return Date{}, fmt.Errorf("nmea: invalid date %q", s)
+	}
+
+	return Date{Valid: true, Day: d, Month: m, Year: fullYear(y)}, nil
+}
+
+// String implements fmt.Stringer interface.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// allDigits reports whether s only has decimal digits. Unlike strconv, it rejects signs.
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// twoDigits returns the value of two decimal digits.
+func twoDigits(s string) int {
+	return int(s[0]-'0')*10 + int(s[1]-'0')
+}
+
+// fullYear maps a two-digit year to 1980-2079, following the GPS epoch.
+func fullYear(yy int) int {
+	if yy < 80 {
+		return 2000 + yy
+	}
+	return 1900 + yy
+}
+
+// DateTime combines the date and the time of day into a UTC time.
+// It returns the zero time if either of them is not valid.
+func DateTime(d Date, t Time) time.Time {
+	if !d.Valid || !t.Valid {
+		return time.Time{}
+	}
+	return time.Date(d.Year, time.Month(d.Month), d.Day,
+		t.Hour, t.Minute, t.Second, t.Millisecond*int(time.Millisecond), time.UTC)
+}
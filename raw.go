@@ -0,0 +1,476 @@
+package gpsd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/vpakhuchyi/go-gpsd/ubx"
+)
+
+// maxPacketSize is the size of the largest packet that can be framed: a UBX packet
+// with the maximum payload, its header and checksum.
+const maxPacketSize = ubx.HeaderLen + ubx.MaxPayloadLen + ubx.ChecksumLen
+
+// Sync bytes of binary receiver protocols.
+const (
+	ubxSync1  = ubx.Sync1
+	ubxSync2  = ubx.Sync2
+	sirfSync1 = 0xa0
+	sirfSync2 = 0xa2
+	sirfEnd1  = 0xb0
+	sirfEnd2  = 0xb3
+	tsipDLE   = 0x10
+	tsipETX   = 0x03
+)
+
+// Size bounds of the SiRF and TSIP packets, beyond which a sync is taken for a false one.
+const (
+	// sirfMaxPayloadLen is the largest payload length allowed by the SiRF binary protocol.
+	sirfMaxPayloadLen = 1023
+	// tsipMaxPacketSize is the size of a TSIP packet with a payload of 255 bytes, all of them DLE,
+	// more than what any TSIP report takes.
+	tsipMaxPacketSize = 2 + 2*255 + 2
+)
+
+// Protocol is the protocol of a packet read in raw mode.
+type Protocol int
+
+const (
+	// ProtocolUnknown marks bytes that could not be framed as a packet of a known protocol.
+	ProtocolUnknown Protocol = iota
+	// ProtocolNMEA is an NMEA 0183 sentence, including its line terminator.
+	ProtocolNMEA
+	// ProtocolJSON is a JSON report of gpsd itself, e.g. a response to a command.
+	ProtocolJSON
+	// ProtocolUBX is a u-blox UBX packet.
+	ProtocolUBX
+	// ProtocolSiRF is a SiRF binary packet.
+	ProtocolSiRF
+	// ProtocolTSIP is a Trimble TSIP packet.
+	ProtocolTSIP
+)
+
+// String implements fmt.Stringer interface.
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolNMEA:
+		return "NMEA"
+	case ProtocolJSON:
+		return "JSON"
+	case ProtocolUBX:
+		return "UBX"
+	case ProtocolSiRF:
+		return "SiRF"
+	case ProtocolTSIP:
+		return "TSIP"
+	default:
+		return "unknown"
+	}
+}
+
+// Packet is a receiver packet relayed by gpsd in raw mode.
+type Packet struct {
+	Protocol Protocol
+	// Data is the complete packet as sent by the receiver, including its framing and checksum.
+	Data []byte
+}
+
+/*
+PacketReader frames a byte stream of receiver packets, such as gpsd's super-raw
+output (WATCH raw:2) or a log recorded with `gpspipe -R`
+
+NMEA sentences and gpsd JSON reports are framed by their line terminator,
+UBX and SiRF packets by their sync bytes, length and checksum, and TSIP packets
+by their DLE/ETX delimiters. Bytes in between are returned as ProtocolUnknown packets,
+so that the stream can be recorded without loss.
+*/
+type PacketReader struct {
+	r *bufio.Reader
+}
+
+// NewPacketReader returns a PacketReader reading from r.
+func NewPacketReader(r io.Reader) *PacketReader {
+	return &PacketReader{r: bufio.NewReaderSize(r, maxPacketSize)}
+}
+
+// Next reads the next packet. It returns io.EOF once the stream ends on a packet boundary.
+func (p *PacketReader) Next() (Packet, error) {
+	head, err := p.r.Peek(1)
+	if err != nil {
+		return Packet{}, err
+	}
+
+	switch head[0] {
+	case '$', '!':
+		return p.readLine(ProtocolNMEA)
+	case '{':
+		return p.readLine(ProtocolJSON)
+	case ubxSync1:
+		if n := p.ubxLen(); n > 0 {
+			return p.read(ProtocolUBX, n)
+		}
+	case sirfSync1:
+		if n := p.sirfLen(); n > 0 {
+			return p.read(ProtocolSiRF, n)
+		}
+	case tsipDLE:
+		if n := p.tsipLen(); n > 0 {
+			return p.read(ProtocolTSIP, n)
+		}
+	}
+	return p.readUnknown()
+}
+
+func (p *PacketReader) readLine(protocol Protocol) (Packet, error) {
+	line, err := p.r.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return Packet{}, err
+	}
+	return Packet{Protocol: protocol, Data: line}, nil
+}
+
+func (p *PacketReader) read(protocol Protocol, n int) (Packet, error) {
+	data := make([]byte, n)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return Packet{}, err
+	}
+	return Packet{Protocol: protocol, Data: data}, nil
+}
+
+// readUnknown reads the first byte and everything up to the next possible start of a packet.
+func (p *PacketReader) readUnknown() (Packet, error) {
+	data := []byte{0}
+	var err error
+	if data[0], err = p.r.ReadByte(); err != nil {
+		return Packet{}, err
+	}
+
+	// Only buffered bytes are consumed, so that unknown data is not held back waiting for more.
+	for p.r.Buffered() > 0 {
+		b, _ := p.r.Peek(1)
+		if isPacketStart(b[0]) {
+			break
+		}
+		data = append(data, b[0])
+		_, _ = p.r.Discard(1)
+	}
+	return Packet{Protocol: ProtocolUnknown, Data: data}, nil
+}
+
+func isPacketStart(b byte) bool {
+	switch b {
+	case '$', '!', '{', ubxSync1, sirfSync1, tsipDLE:
+		return true
+	default:
+		return false
+	}
+}
+
+// ubxLen returns the length of the UBX packet at the head of the stream, or 0 if there is none.
+// It waits for the payload only once ubx.Frame has accepted the header.
+func (p *PacketReader) ubxLen() int {
+	for n := ubx.HeaderLen; ; {
+		data, err := p.r.Peek(n)
+		m, ferr := ubx.Frame(data)
+		switch {
+		case ferr == nil:
+			return m
+		case ferr != ubx.ErrIncomplete, err != nil:
+			return 0
+		}
+		n = m
+	}
+}
+
+// sirfLen returns the length of the SiRF packet at the head of the stream, or 0 if there is none:
+// 0xA0 0xA2, big-endian 15-bit payload length, payload, 15-bit checksum and 0xB0 0xB3.
+func (p *PacketReader) sirfLen() int {
+	header, err := p.r.Peek(4)
+	if err != nil || header[1] != sirfSync2 {
+		return 0
+	}
+	length := int(binary.BigEndian.Uint16(header[2:]) & 0x7fff)
+	if length > sirfMaxPayloadLen {
+		return 0
+	}
+	n := 4 + length + 4
+	packet, err := p.r.Peek(n)
+	if err != nil || packet[n-2] != sirfEnd1 || packet[n-1] != sirfEnd2 {
+		return 0
+	}
+
+	var sum uint16
+	for _, c := range packet[4 : 4+length] {
+		sum += uint16(c)
+	}
+	if sum&0x7fff != binary.BigEndian.Uint16(packet[n-4:]) {
+		return 0
+	}
+	return n
+}
+
+// tsipLen returns the length of the TSIP packet at the head of the stream, or 0 if there is none:
+// DLE, packet ID, payload with every DLE doubled and DLE ETX. A DLE followed by anything else
+// ends the scan right away, and so does reaching tsipMaxPacketSize.
+func (p *PacketReader) tsipLen() int {
+	i := 2
+	for n := 2; ; {
+		packet, err := p.r.Peek(n)
+		if err != nil || packet[1] == tsipDLE || packet[1] == tsipETX {
+			return 0
+		}
+		for ; i+1 < len(packet); i++ {
+			if packet[i] != tsipDLE {
+				continue
+			}
+			switch packet[i+1] {
+			case tsipETX:
+				return i + 2
+			case tsipDLE:
+				i++
+			default:
+				return 0
+			}
+		}
+
+		if n == tsipMaxPacketSize {
+			return 0
+		}
+		// Wait for more data only when everything buffered has been scanned.
+		if buffered := p.r.Buffered(); buffered > n {
+			n = buffered
+		} else {
+			n++
+		}
+		if n > tsipMaxPacketSize {
+			n = tsipMaxPacketSize
+		}
+	}
+}
+
+// rawPacket returns the packet of a line read in raw mode (WATCH raw:1):
+// gpsd passes NMEA sentences and its own JSON reports as they are
+// and dumps binary packets as hex.
+func rawPacket(line string) (Packet, error) {
+	switch {
+	case strings.HasPrefix(line, "{"):
+		return Packet{Protocol: ProtocolJSON, Data: []byte(line)}, nil
+	case strings.HasPrefix(line, "$"), strings.HasPrefix(line, "!"):
+		return Packet{Protocol: ProtocolNMEA, Data: []byte(line)}, nil
+	}
+
+	data, err := hex.DecodeString(strings.TrimSpace(line))
+	if err != nil {
+		return Packet{}, err
+	}
+	return Packet{Protocol: binaryProtocol(data), Data: data}, nil
+}
+
+// binaryProtocol detects the protocol of a binary packet from its sync bytes.
+func binaryProtocol(data []byte) Protocol {
+	switch {
+	case bytes.HasPrefix(data, []byte{ubxSync1, ubxSync2}):
+		return ProtocolUBX
+	case bytes.HasPrefix(data, []byte{sirfSync1, sirfSync2}):
+		return ProtocolSiRF
+	case len(data) > 0 && data[0] == tsipDLE:
+		return ProtocolTSIP
+	default:
+		return ProtocolUnknown
+	}
+}
+
+/*
+SubscribePackets returns a channel receiving every receiver packet read by the session
+in raw and super-raw watch modes:
+
+	packets := gpsd.SubscribePackets(session, 64)
+	for p := range packets {
+		if p.Protocol == gpsd.ProtocolUBX {
+			log.Write(p.Data)
+		}
+	}
+
+The buffering and drop policy are the same as for Subscribe.
+*/
+func SubscribePackets(s *Session, size int) <-chan Packet {
+	if size < 1 {
+		size = 1
+	}
+	sub := &subscription[Packet]{ch: make(chan Packet, size)}
+
+	s.subscribePackets(sub.send)
+	s.onClose(sub.close)
+
+	return sub.ch
+}
+
+// SubscribePacketsFunc registers f to be called with every receiver packet read by the session.
+// f is called synchronously from the session goroutine, so it must not block
+// nor issue requests on the session, see Request.
+func SubscribePacketsFunc(s *Session, f func(Packet)) {
+	s.subscribePackets(f)
+}
+
+/*
+SubscribeUBX returns a channel receiving every UBX message of type T read by the session
+in raw and super-raw watch modes, decoded and with a valid checksum:
+
+	pvt := gpsd.SubscribeUBX[ubx.NavPVT](session, 16)
+	for m := range pvt {
+		fmt.Println(m.Lat, m.Lon, m.CarrierSolution)
+	}
+
+Use ubx.Message as T to receive messages of all types.
+The buffering and drop policy are the same as for Subscribe.
+*/
+func SubscribeUBX[T ubx.Message](s *Session, size int) <-chan T {
+	if size < 1 {
+		size = 1
+	}
+	sub := &subscription[T]{ch: make(chan T, size)}
+
+	s.subscribeUBX(func(m ubx.Message) {
+		if v, ok := m.(T); ok {
+			sub.send(v)
+		}
+	})
+	s.onClose(sub.close)
+
+	return sub.ch
+}
+
+// SubscribeUBXFunc registers f to be called with every UBX message of type T read by the session.
+// f is called synchronously from the session goroutine, so it must not block
+// nor issue requests on the session, see Request.
+func SubscribeUBXFunc[T ubx.Message](s *Session, f func(T)) {
+	s.subscribeUBX(func(m ubx.Message) {
+		if v, ok := m.(T); ok {
+			f(v)
+		}
+	})
+}
+
+// subscribeUBX registers f to be called with every decoded UBX message.
+func (s *Session) subscribeUBX(f func(ubx.Message)) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	s.ubxFilters = append(s.ubxFilters, f)
+}
+
+// hasUBXFilters reports whether there is at least one subscriber for UBX messages.
+func (s *Session) hasUBXFilters() bool {
+	s.filtersMu.RLock()
+	defer s.filtersMu.RUnlock()
+	return len(s.ubxFilters) > 0
+}
+
+// handleUBX decodes the packet for UBX subscribers.
+func (s *Session) handleUBX(packet Packet) {
+	if !s.hasUBXFilters() {
+		return
+	}
+
+	m, err := ubx.Parse(packet.Data)
+	if err != nil {
+		s.logger.Warn("gpsd: failed to parse UBX packet", "address", s.address, "error", err)
+		s.reportError(&DecodeError{Class: msgClassUBX, Line: hex.EncodeToString(packet.Data), Err: err})
+		return
+	}
+
+	s.filtersMu.RLock()
+	filters := s.ubxFilters
+	s.filtersMu.RUnlock()
+	for _, f := range filters {
+		f(m)
+	}
+}
+
+// subscribePackets registers f to be called with every packet read in raw mode.
+func (s *Session) subscribePackets(f func(Packet)) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	s.packetFilters = append(s.packetFilters, f)
+}
+
+func (s *Session) deliverPacket(packet Packet) {
+	s.filtersMu.RLock()
+	filters := s.packetFilters
+	s.filtersMu.RUnlock()
+
+	for _, f := range filters {
+		f(packet)
+	}
+}
+
+// watchRaw reads lines of raw mode, with binary packets dumped as hex.
+func (s *Session) watchRaw(ctx context.Context) error {
+	conn, _ := s.conn()
+	defer interruptOnDone(ctx, conn)()
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+
+		packet, err := rawPacket(line)
+		if err != nil {
+			s.logger.Warn("gpsd: failed to decode raw packet", "address", s.address, "line", line, "error", err)
+			s.reportError(&DecodeError{Line: line, Err: err})
+			continue
+		}
+		s.handlePacket(packet)
+	}
+}
+
+// watchSuperRaw reads the binary passthrough of super-raw mode.
+func (s *Session) watchSuperRaw(ctx context.Context) error {
+	conn, _ := s.conn()
+	defer interruptOnDone(ctx, conn)()
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		packet, err := s.readPacket()
+		if err != nil {
+			return err
+		}
+
+		s.handlePacket(packet)
+	}
+}
+
+// readPacket reads the next packet from the connection.
+func (s *Session) readPacket() (Packet, error) {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+
+	// The session reader is large enough to be used as it is.
+	_, reader := s.conn()
+	return NewPacketReader(reader).Next()
+}
+
+// handlePacket delivers the packet to packet subscribers. gpsd reports are handled
+// as in json mode, NMEA sentences as in nmea mode and UBX packets are decoded for UBX subscribers.
+func (s *Session) handlePacket(packet Packet) {
+	switch packet.Protocol {
+	case ProtocolJSON:
+		s.handleJSON(string(packet.Data))
+		return
+	case ProtocolNMEA:
+		s.handleNMEA(string(packet.Data))
+	case ProtocolUBX:
+		s.handleUBX(packet)
+	}
+	s.deliverPacket(packet)
+}
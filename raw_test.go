@@ -0,0 +1,89 @@
+package gpsd_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/vpakhuchyi/go-gpsd"
+)
+
+// readPackets frames the packets written to the returned pipe until the end of the test.
+func readPackets(t *testing.T) (*io.PipeWriter, <-chan gpsd.Packet) {
+	t.Helper()
+	r, w := io.Pipe()
+	t.Cleanup(func() { _ = w.Close() })
+
+	packets := make(chan gpsd.Packet, 64)
+	go func() {
+		defer close(packets)
+		reader := gpsd.NewPacketReader(r)
+		for {
+			p, err := reader.Next()
+			if err != nil {
+				return
+			}
+			packets <- p
+		}
+	}()
+	return w, packets
+}
+
+func expectPackets(t *testing.T, packets <-chan gpsd.Packet, want ...gpsd.Packet) {
+	t.Helper()
+	for _, w := range want {
+		if p := receive(t, packets); p.Protocol != w.Protocol || !bytes.Equal(p.Data, w.Data) {
+			t.Fatalf("packet = %v % x, want %v % x", p.Protocol, p.Data, w.Protocol, w.Data)
+		}
+	}
+}
+
+const gga = "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n"
+
+func unknown(data ...byte) gpsd.Packet {
+	return gpsd.Packet{Protocol: gpsd.ProtocolUnknown, Data: data}
+}
+
+func TestPacketReaderSiRFFalseSync(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+	}{
+		{name: "length above 1023", header: []byte{0xa0, 0xa2, 0x04, 0x00}},
+		{name: "largest length", header: []byte{0xa0, 0xa2, 0x7f, 0xff}},
+		{name: "no end sequence", header: []byte{0xa0, 0xa2, 0x00, 0x08}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, packets := readPackets(t)
+			// The stream stays open: the sentence must not wait for the length of the false packet.
+			go func() { _, _ = w.Write(append(tt.header, gga...)) }()
+			expectPackets(t, packets, unknown(tt.header...), gpsd.Packet{Protocol: gpsd.ProtocolNMEA, Data: []byte(gga)})
+		})
+	}
+}
+
+func TestPacketReaderTSIPFalseSync(t *testing.T) {
+	w, packets := readPackets(t)
+	sentence := gpsd.Packet{Protocol: gpsd.ProtocolNMEA, Data: []byte(gga)}
+
+	// A DLE followed by '$' can't be part of a TSIP packet, so the scan stops there.
+	go func() { _, _ = w.Write(append([]byte{0x10, 0x41, 0x10}, gga...)) }()
+	expectPackets(t, packets, unknown(0x10, 0x41))
+
+	// The last DLE is then followed by sentences without any DLE,
+	// given up on once they exceed the size of any TSIP packet.
+	n := 600/len(gga) + 1
+	go func() { _, _ = w.Write([]byte(strings.Repeat(gga, n))) }()
+	expectPackets(t, packets, unknown(0x10))
+	for i := 0; i < n+1; i++ {
+		expectPackets(t, packets, sentence)
+	}
+
+	// Packets are framed again afterwards, with their doubled DLEs.
+	tsip := []byte{0x10, 0x41, 0x10, 0x10, 0x01, 0x10, 0x03}
+	go func() { _, _ = w.Write(append(tsip, gga...)) }()
+	expectPackets(t, packets, gpsd.Packet{Protocol: gpsd.ProtocolTSIP, Data: tsip}, sentence)
+}
@@ -0,0 +1,185 @@
+package gpsd
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultReconnectPolicy is used by sessions created without WithReconnectPolicy.
+// It retries forever, starting after one second and backing off up to 30 seconds.
+var DefaultReconnectPolicy ReconnectPolicy = &Backoff{
+	Initial:    time.Second,
+	Max:        30 * time.Second,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// ReconnectPolicy decides whether and when a lost connection to gpsd is re-established.
+type ReconnectPolicy interface {
+	// Next is called before each reconnection attempt, starting with 1, together with
+	// the error that caused the disconnect or made the previous attempt fail.
+	// It returns the delay before the attempt, or false to give up.
+	Next(attempt int, err error) (delay time.Duration, ok bool)
+}
+
+// ReconnectFunc is an adapter to allow the use of ordinary functions as a ReconnectPolicy.
+type ReconnectFunc func(attempt int, err error) (time.Duration, bool)
+
+// Next calls f(attempt, err).
+func (f ReconnectFunc) Next(attempt int, err error) (time.Duration, bool) {
+	return f(attempt, err)
+}
+
+// NoReconnect is a ReconnectPolicy that never reconnects:
+// RunContext returns as soon as the connection is lost.
+var NoReconnect ReconnectPolicy = ReconnectFunc(func(int, error) (time.Duration, bool) {
+	return 0, false
+})
+
+// Backoff is a ReconnectPolicy with exponential backoff and random jitter.
+//
+// The delay before attempt n is Initial * Multiplier^(n-1), capped at Max, and then
+// randomly spread by +/- Jitter (a fraction of the delay) to avoid reconnect storms
+// when many clients lose the same gpsd at once.
+type Backoff struct {
+	// Initial delay before the first attempt. Defaults to one second.
+	Initial time.Duration
+	// Max caps the delay between attempts. Zero means no cap.
+	Max time.Duration
+	// Multiplier applied to the delay after every failed attempt. Defaults to 2.
+	Multiplier float64
+	// Jitter is a fraction in [0, 1] of the delay used to randomise it.
+	Jitter float64
+	// MaxAttempts limits the number of consecutive attempts. Zero means no limit.
+	MaxAttempts int
+	// OnGiveUp, if set, is called with the last error once MaxAttempts is exhausted.
+	OnGiveUp func(err error)
+
+	// random returns numbers in [0, 1) for the jitter, rand.Float64 if nil.
+	random func() float64
+}
+
+// Next implements ReconnectPolicy.
+func (b *Backoff) Next(attempt int, err error) (time.Duration, bool) {
+	if b.MaxAttempts > 0 && attempt > b.MaxAttempts {
+		if b.OnGiveUp != nil {
+			b.OnGiveUp(err)
+		}
+		return 0, false
+	}
+
+	initial := b.Initial
+	if initial <= 0 {
+		initial = time.Second
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		random := b.random
+		if random == nil {
+			random = rand.Float64
+		}
+		delay += delay * b.Jitter * (2*random() - 1)
+	}
+
+	// Without Max the delay grows to +Inf, whose conversion to a Duration is undefined.
+	if delay >= float64(maxDelay) {
+		return maxDelay, true
+	}
+	return time.Duration(delay), true
+}
+
+// maxDelay is the longest delay a Backoff returns.
+const maxDelay = time.Duration(math.MaxInt64)
+
+// ConnState is a state of the connection to gpsd.
+type ConnState int
+
+const (
+	// StateConnecting indicates that a connection attempt is in progress.
+	StateConnecting ConnState = iota
+	// StateConnected indicates that the session is connected and streaming.
+	StateConnected
+	// StateDisconnected indicates that the connection has been lost.
+	StateDisconnected
+	// StateGaveUp indicates that the reconnect policy gave up and the stream has stopped.
+	StateGaveUp
+)
+
+// String implements fmt.Stringer interface.
+func (c ConnState) String() string {
+	switch c {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateGaveUp:
+		return "gave up"
+	default:
+		return "unknown"
+	}
+}
+
+// StateEvent describes a change of the connection state.
+type StateEvent struct {
+	State ConnState
+	// Address of gpsd the session is connected to.
+	Address string
+	// Attempt is the number of the reconnection attempt, 0 for the initial connection.
+	Attempt int
+	// Err is the reason of a disconnect, a failed attempt or giving up.
+	Err error
+}
+
+// WithReconnectPolicy sets the policy used by RunContext to re-establish a lost connection.
+func WithReconnectPolicy(p ReconnectPolicy) Option {
+	return func(s *Session) {
+		s.reconnect = p
+	}
+}
+
+// WithStateHandler registers f to observe connection state changes, including
+// the ones emitted while the session is being dialed. See Session.OnStateChange.
+func WithStateHandler(f func(StateEvent)) Option {
+	return func(s *Session) {
+		s.stateHandlers = append(s.stateHandlers, f)
+	}
+}
+
+// OnStateChange registers f to be called on every connection state change.
+// f is called synchronously from the session goroutine, so it must not block
+// nor issue requests on the session, see Request.
+func (s *Session) OnStateChange(f func(StateEvent)) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	s.stateHandlers = append(s.stateHandlers, f)
+}
+
+// State returns the current connection state.
+func (s *Session) State() ConnState {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return s.state
+}
+
+func (s *Session) setState(state ConnState, attempt int, err error) {
+	s.stateMu.Lock()
+	s.state = state
+	handlers := s.stateHandlers
+	s.stateMu.Unlock()
+
+	ev := StateEvent{State: state, Address: s.address, Attempt: attempt, Err: err}
+	for _, f := range handlers {
+		f(ev)
+	}
+}
@@ -0,0 +1,77 @@
+package gpsd
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		// random is the value returned by the random source.
+		random  float64
+		attempt int
+		want    time.Duration
+	}{
+		{name: "defaults", attempt: 1, want: time.Second},
+		{name: "defaults growing", attempt: 4, want: 8 * time.Second},
+		{name: "multiplier", backoff: Backoff{Initial: 100 * time.Millisecond, Multiplier: 3}, attempt: 3, want: 900 * time.Millisecond},
+		{name: "multiplier below 1", backoff: Backoff{Initial: time.Second, Multiplier: 0.5}, attempt: 2, want: 2 * time.Second},
+		{name: "capped", backoff: Backoff{Initial: time.Second, Max: 5 * time.Second}, attempt: 10, want: 5 * time.Second},
+		{name: "jitter low", backoff: Backoff{Initial: time.Second, Jitter: 0.2}, random: 0, attempt: 1, want: 800 * time.Millisecond},
+		{name: "jitter high", backoff: Backoff{Initial: time.Second, Jitter: 0.2}, random: 1, attempt: 1, want: 1200 * time.Millisecond},
+		{name: "jitter after cap", backoff: Backoff{Initial: time.Second, Max: 10 * time.Second, Jitter: 0.5}, random: 0, attempt: 20, want: 5 * time.Second},
+		{name: "unbounded", backoff: Backoff{Initial: time.Second}, attempt: 2000, want: maxDelay},
+		{name: "unbounded with jitter", backoff: Backoff{Initial: time.Second, Jitter: 1}, random: 0.75, attempt: 2000, want: maxDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.backoff
+			b.random = func() float64 { return tt.random }
+
+			got, ok := b.Next(tt.attempt, nil)
+			if !ok {
+				t.Fatalf("Next(%d) gave up", tt.attempt)
+			}
+			// Allow for the rounding of the float computation.
+			if math.Abs(float64(got-tt.want)) > 1 {
+				t.Errorf("Next(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffJitterRange(t *testing.T) {
+	b := &Backoff{Initial: time.Second, Jitter: 0.25}
+	for i := 0; i < 1000; i++ {
+		got, _ := b.Next(1, nil)
+		if got < 750*time.Millisecond || got > 1250*time.Millisecond {
+			t.Fatalf("Next(1) = %v, want within 1s +/- 25%%", got)
+		}
+	}
+}
+
+func TestBackoffMaxAttempts(t *testing.T) {
+	errLost := errors.New("connection lost")
+	var gaveUp []error
+	b := &Backoff{Initial: time.Millisecond, MaxAttempts: 3, OnGiveUp: func(err error) { gaveUp = append(gaveUp, err) }}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if _, ok := b.Next(attempt, errLost); !ok {
+			t.Fatalf("Next(%d) gave up, want a retry", attempt)
+		}
+	}
+	if len(gaveUp) != 0 {
+		t.Fatalf("OnGiveUp called before MaxAttempts is exhausted")
+	}
+	if delay, ok := b.Next(4, errLost); ok || delay != 0 {
+		t.Errorf("Next(4) = %v, %v, want 0, false", delay, ok)
+	}
+	if len(gaveUp) != 1 || gaveUp[0] != errLost {
+		t.Errorf("OnGiveUp called with %v, want [%v]", gaveUp, errLost)
+	}
+}
@@ -0,0 +1,241 @@
+package gpsd
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder receives every line read from gpsd together with the time it was received,
+// e.g. to record the session to disk. See FileRecorder.
+//
+// The lines include the VERSION banner of every connection. The binary output
+// of super-raw mode (WATCH raw:2) is not recorded: record it from SubscribePackets.
+type Recorder interface {
+	Record(received time.Time, line string) error
+}
+
+// WithRecorder sets the recorder teeing every line read by the session.
+// Record is called synchronously from the session goroutine, so it must not block.
+// Its errors are reported to the OnError handlers as *ConnError with Op "record".
+func WithRecorder(r Recorder) Option {
+	return func(s *Session) {
+		s.recorder = r
+	}
+}
+
+// record tees the line to the recorder of the session, if any.
+func (s *Session) record(received time.Time, line string) {
+	if s.recorder == nil {
+		return
+	}
+	if err := s.recorder.Record(received, line); err != nil {
+		s.logger.Warn("gpsd: failed to record line", "address", s.address, "line", line, "error", err)
+		s.reportError(&ConnError{Op: "record", Address: s.address, Err: err})
+	}
+}
+
+// RecordFormat is the format of the files written by a FileRecorder.
+type RecordFormat int
+
+const (
+	// RecordGpspipe writes the lines as they were received, like `gpspipe -w` or `gpspipe -r`.
+	RecordGpspipe RecordFormat = iota
+	// RecordJSONLines wraps every line in a JSON object with its receive time:
+	//
+	//	{"time":"2026-10-16T12:30:45.123456789Z","mono":1500000000,"line":"{\"class\":\"TPV\",...}\r\n"}
+	//
+	// "time" is the wall clock time and "mono" the monotonic time in nanoseconds since the recorder
+	// was created, which is immune to clock steps and is used to pace a Replayer.
+	RecordJSONLines
+)
+
+// RecorderConfig configures a FileRecorder.
+type RecorderConfig struct {
+	// Path of the log, e.g. "/var/log/gps/session.log". Every file is named after it
+	// with the time it was opened and a sequence number telling apart the files opened
+	// within the same millisecond, e.g. "/var/log/gps/session-20261016T123045.000-0000.log",
+	// so that the files sort in the order they were written.
+	Path   string
+	Format RecordFormat
+	// MaxSize rotates the file once that many bytes have been recorded into it, before compression.
+	// Zero disables size-based rotation.
+	MaxSize int64
+	// MaxAge rotates the file once it has been open for that long. Zero disables time-based rotation.
+	MaxAge time.Duration
+	// Gzip compresses the files, which get a ".gz" extension.
+	// The compressed data is flushed every gzipFlushInterval, so that a file being
+	// written can be followed and little is lost if the process dies.
+	Gzip bool
+}
+
+// gzipFlushInterval is the longest time recorded lines stay buffered by gzip.
+const gzipFlushInterval = time.Second
+
+// recordEntry is a line of a RecordJSONLines file.
+type recordEntry struct {
+	Time time.Time `json:"time"`
+	Mono int64     `json:"mono"`
+	Line string    `json:"line"`
+}
+
+/*
+FileRecorder is a Recorder writing the lines into rotating files
+
+	recorder, err := gpsd.NewFileRecorder(gpsd.RecorderConfig{
+		Path:    "/var/log/gps/session.log",
+		Format:  gpsd.RecordJSONLines,
+		MaxSize: 64 << 20,
+		Gzip:    true,
+	})
+	session, err := gpsd.Dial(gpsd.DefaultAddress, gpsd.WithRecorder(recorder))
+	defer recorder.Close()
+
+The files can be replayed with a Replayer.
+*/
+type FileRecorder struct {
+	cfg   RecorderConfig
+	start time.Time
+
+	mu     sync.Mutex
+	closed bool
+	file   *os.File
+	gz     *gzip.Writer
+	w      io.Writer
+	size   int64
+	opened time.Time
+	// flush is pending while the gzip writer holds unflushed data.
+	flush *time.Timer
+}
+
+// NewFileRecorder creates the directory of the log if needed and opens its first file.
+func NewFileRecorder(cfg RecorderConfig) (*FileRecorder, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("gpsd: recorder path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &FileRecorder{cfg: cfg, start: time.Now()}
+	if err := r.open(r.start); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Record implements Recorder interface.
+func (r *FileRecorder) Record(received time.Time, line string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return os.ErrClosed
+	}
+	if r.w != nil && r.rotationDue(received) {
+		if err := r.close(); err != nil {
+			return err
+		}
+	}
+	// The file is missing after a failed rotation, try again.
+	if r.w == nil {
+		if err := r.open(received); err != nil {
+			return err
+		}
+	}
+
+	data := []byte(line)
+	if r.cfg.Format == RecordJSONLines {
+		var err error
+		data, err = json.Marshal(recordEntry{Time: received, Mono: int64(received.Sub(r.start)), Line: line})
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	}
+
+	n, err := r.w.Write(data)
+	r.size += int64(n)
+	if r.gz != nil && r.flush == nil {
+		r.flush = time.AfterFunc(gzipFlushInterval, r.flushGzip)
+	}
+	return err
+}
+
+// flushGzip flushes the compressed data to the file. A failure is returned by the next
+// Record, as the gzip writer keeps failing after an error.
+func (r *FileRecorder) flushGzip() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flush = nil
+	if r.gz != nil {
+		_ = r.gz.Flush()
+	}
+}
+
+// Close flushes and closes the current file.
+func (r *FileRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return r.close()
+}
+
+func (r *FileRecorder) rotationDue(now time.Time) bool {
+	return (r.cfg.MaxSize > 0 && r.size >= r.cfg.MaxSize) ||
+		(r.cfg.MaxAge > 0 && now.Sub(r.opened) >= r.cfg.MaxAge)
+}
+
+// open creates the next file of the log, named after the time it is opened.
+func (r *FileRecorder) open(now time.Time) error {
+	ext := filepath.Ext(r.cfg.Path)
+	base := strings.TrimSuffix(r.cfg.Path, ext) + "-" + now.UTC().Format("20060102T150405.000")
+	if r.cfg.Gzip {
+		ext += ".gz"
+	}
+
+	// Don't overwrite a file opened within the same millisecond.
+	var file *os.File
+	err := os.ErrExist
+	for seq := 0; errors.Is(err, os.ErrExist); seq++ {
+		name := fmt.Sprintf("%s-%04d%s", base, seq, ext)
+		file, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.file, r.w, r.size, r.opened = file, file, 0, now
+	if r.cfg.Gzip {
+		r.gz = gzip.NewWriter(file)
+		r.w = r.gz
+	}
+	return nil
+}
+
+func (r *FileRecorder) close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	if r.flush != nil {
+		r.flush.Stop()
+		r.flush = nil
+	}
+	var err error
+	if r.gz != nil {
+		err = r.gz.Close()
+	}
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file, r.gz, r.w = nil, nil, nil
+	return err
+}
@@ -0,0 +1,241 @@
+package gpsd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	recordTPV = `{"class":"TPV","mode":3,"lat":50.45,"lon":30.52}` + "\r\n"
+	recordGGA = "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n"
+)
+
+// recordedFiles returns the base names of the files of the log in the directory, in order.
+func recordedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range names {
+		names[i] = filepath.Base(name)
+	}
+	return names
+}
+
+// readRecorded returns the content of the file, uncompressed as far as it has been flushed.
+func readRecorded(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(name) != ".gz" {
+		return string(data)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader(%s) error = %v", name, err)
+	}
+	data, err = io.ReadAll(gz)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("reading %s error = %v", name, err)
+	}
+	return string(data)
+}
+
+func newTestRecorder(t *testing.T, cfg RecorderConfig) *FileRecorder {
+	t.Helper()
+	r, err := NewFileRecorder(cfg)
+	if err != nil {
+		t.Fatalf("NewFileRecorder() error = %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func record(t *testing.T, r *FileRecorder, received time.Time, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if err := r.Record(received, line); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+}
+
+// recordedLog checks the files of the log: one opened when the recorder was created,
+// named after the current time, then the given rotated ones, with their content.
+func recordedLog(t *testing.T, dir, first string, rotated map[string]string) {
+	t.Helper()
+	files := recordedFiles(t, dir)
+	if len(files) != len(rotated)+1 {
+		t.Fatalf("files = %v, want %d", files, len(rotated)+1)
+	}
+	for _, name := range files {
+		want, ok := rotated[name]
+		if !ok {
+			if !strings.HasPrefix(name, "session-") {
+				t.Errorf("file %s is not named after the log", name)
+			}
+			want = first
+		}
+		if got := readRecorded(t, filepath.Join(dir, name)); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFileRecorderSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRecorder(t, RecorderConfig{Path: filepath.Join(dir, "session.log"), MaxSize: int64(len(recordTPV) + 1)})
+	at := time.Date(2026, 10, 16, 12, 30, 45, 0, time.UTC)
+
+	// The size is exceeded by the second line only, the file is rotated before the third one.
+	record(t, r, at, recordTPV, recordGGA)
+	record(t, r, at.Add(1500*time.Millisecond), recordTPV)
+	// Files opened within the same millisecond are told apart by their sequence number.
+	record(t, r, at.Add(1500*time.Millisecond), recordGGA, recordTPV)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	recordedLog(t, dir, recordTPV+recordGGA, map[string]string{
+		"session-20261016T123046.500-0000.log": recordTPV + recordGGA,
+		"session-20261016T123046.500-0001.log": recordTPV,
+	})
+}
+
+func TestFileRecorderTimeRotation(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRecorder(t, RecorderConfig{Path: filepath.Join(dir, "session.log"), MaxAge: time.Minute})
+	now := time.Now()
+
+	record(t, r, now, recordTPV)
+	record(t, r, now.Add(59*time.Second), recordGGA)
+	// The file opened after a minute is named after the time of the line that triggered the rotation.
+	at := now.Add(time.Minute)
+	record(t, r, at, recordTPV)
+	record(t, r, at.Add(time.Minute-time.Millisecond), recordGGA)
+	next := at.Add(2 * time.Minute)
+	record(t, r, next, recordTPV)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	name := func(at time.Time) string {
+		return "session-" + at.UTC().Format("20060102T150405.000") + "-0000.log"
+	}
+	recordedLog(t, dir, recordTPV+recordGGA, map[string]string{
+		name(at):   recordTPV + recordGGA,
+		name(next): recordTPV,
+	})
+}
+
+func TestFileRecorderJSONLines(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRecorder(t, RecorderConfig{Path: filepath.Join(dir, "session.jsonl"), Format: RecordJSONLines})
+	received := []time.Time{r.start.Add(time.Millisecond), r.start.Add(1500 * time.Millisecond)}
+	record(t, r, received[0], recordTPV)
+	record(t, r, received[1], recordGGA)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	files := recordedFiles(t, dir)
+	if len(files) != 1 || filepath.Ext(files[0]) != ".jsonl" {
+		t.Fatalf("files = %v, want one .jsonl file", files)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(readRecorded(t, filepath.Join(dir, files[0]))))
+	var entries []recordEntry
+	for scanner.Scan() {
+		var entry recordEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %s: %v", scanner.Text(), err)
+		}
+		// The entry is also recognised by the replayer.
+		if _, ok := recordedEntry(scanner.Text()); !ok {
+			t.Errorf("recordedEntry(%s) = false", scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+
+	want := []recordEntry{
+		{Time: received[0], Mono: int64(time.Millisecond), Line: recordTPV},
+		{Time: received[1], Mono: int64(1500 * time.Millisecond), Line: recordGGA},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if !entries[i].Time.Equal(want[i].Time) || entries[i].Mono != want[i].Mono || entries[i].Line != want[i].Line {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestFileRecorderGzip(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRecorder(t, RecorderConfig{Path: filepath.Join(dir, "session.log"), Gzip: true})
+	record(t, r, time.Now(), recordTPV)
+
+	files := recordedFiles(t, dir)
+	if len(files) != 1 || !strings.HasSuffix(files[0], ".log.gz") {
+		t.Fatalf("files = %v, want one .log.gz file", files)
+	}
+	name := filepath.Join(dir, files[0])
+
+	// The line stays buffered by gzip until the flush that Record has scheduled,
+	// which is run right away instead of waiting for it.
+	r.mu.Lock()
+	pending := r.flush != nil && r.flush.Stop()
+	r.mu.Unlock()
+	if !pending {
+		t.Fatal("no flush scheduled after Record")
+	}
+	if got := readRecorded(t, name); got != "" {
+		t.Errorf("%s = %q before the flush, want nothing", name, got)
+	}
+	r.flushGzip()
+	if got := readRecorded(t, name); got != recordTPV {
+		t.Errorf("%s = %q after the flush, want %q", name, got, recordTPV)
+	}
+
+	// The next line schedules another flush, and closing the file writes it.
+	record(t, r, time.Now(), recordGGA)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := readRecorded(t, name); got != recordTPV+recordGGA {
+		t.Errorf("%s = %q, want %q", name, got, recordTPV+recordGGA)
+	}
+	if err := r.Record(time.Now(), recordTPV); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Record() after Close() error = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+// failingRecorder fails to record every line.
+type failingRecorder struct{}
+
+func (failingRecorder) Record(time.Time, string) error { return os.ErrPermission }
+
+func TestRecordError(t *testing.T) {
+	s := newSession("localhost:2947", WithRecorder(failingRecorder{}))
+	var errs []error
+	s.OnError(func(err error) { errs = append(errs, err) })
+	s.record(time.Now(), recordTPV)
+
+	var connErr *ConnError
+	if len(errs) != 1 || !errors.As(errs[0], &connErr) || connErr.Op != "record" ||
+		connErr.Address != "localhost:2947" || !errors.Is(errs[0], os.ErrPermission) {
+		t.Errorf("errors = %v, want a *ConnError of the record operation", errs)
+	}
+}
@@ -0,0 +1,250 @@
+package gpsd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vpakhuchyi/go-gpsd/nmea"
+)
+
+// Replay speeds.
+const (
+	// ReplayRealTime paces the replay as the log was recorded.
+	ReplayRealTime = 1
+	// ReplayAsFastAsPossible replays the log without any pacing.
+	ReplayAsFastAsPossible = 0
+)
+
+/*
+Replayer feeds a recorded gpsd session through the same dispatch path as a live one,
+so that applications can be tested without a running gpsd
+
+The log is read line by line, e.g. the output of `gpspipe -w` (JSON reports),
+`gpspipe -r` (NMEA sentences), both, or a file written by a FileRecorder
+(wrap the reader with gzip.NewReader for compressed files). Subscribers of
+the replayer's session receive reports and sentences exactly as they would
+from a live session:
+
+	replayer := gpsd.NewReplayer(file)
+	replayer.Speed = 10
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](replayer.Session(), 16)
+	go replayer.Run(ctx)
+
+Lines are paced by the timestamps of the reports: the "time" field of JSON reports
+and the UTC time of NMEA sentences, or by the receive time of lines recorded
+in the RecordJSONLines format. Lines without a timestamp are delivered
+together with the preceding ones.
+*/
+type Replayer struct {
+	// Speed of the replay relative to the recording: ReplayRealTime, a factor
+	// such as 10 for an accelerated replay, or ReplayAsFastAsPossible.
+	Speed float64
+
+	r       io.Reader
+	session *Session
+	// now and sleep give the wall time pacing the replay, time.Now and sleepContext if nil.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewReplayer returns a Replayer of the log read from r, replayed in real time.
+// The options configure the session of the replayer, e.g. its logger.
+func NewReplayer(r io.Reader, opts ...Option) *Replayer {
+	return &Replayer{
+		Speed:   ReplayRealTime,
+		r:       r,
+		session: newSession("replay", opts...),
+	}
+}
+
+// Session returns the session fed by the replayer, to subscribe to its reports.
+// It is not connected to gpsd, so commands sent through it fail with ErrNotConnected.
+func (r *Replayer) Session() *Session {
+	return r.session
+}
+
+// Run replays the log until its end, ctx is done or the session is closed.
+// It returns nil once the whole log has been replayed, ctx.Err() on cancellation,
+// ErrClosed after the session has been closed, or the error reading the log.
+func (r *Replayer) Run(ctx context.Context) error {
+	s := r.session
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.streaming.Add(1)
+	defer s.streaming.Add(-1)
+
+	now, sleep := r.now, r.sleep
+	if now == nil {
+		now = time.Now
+	}
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	var (
+		reader  = bufio.NewReader(r.r)
+		clock   replayClock
+		start   = now()
+		elapsed time.Duration
+	)
+	for {
+		if ctx.Err() != nil {
+			return s.runErr(ctx)
+		}
+
+		line, err := reader.ReadString('\n')
+		if strings.TrimSpace(line) != "" {
+			paced := false
+			if entry, ok := recordedEntry(line); ok {
+				line = entry.Line
+				elapsed, paced = clock.advanceMono(time.Duration(entry.Mono)), true
+			} else if stamp, ok := lineTimestamp(line); ok {
+				elapsed, paced = clock.advance(stamp), true
+			}
+			if paced && r.Speed > 0 {
+				delay := start.Add(time.Duration(float64(elapsed) / r.Speed)).Sub(now())
+				if err := sleep(ctx, delay); err != nil {
+					return s.runErr(ctx)
+				}
+			}
+			s.handleLine(line)
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// replayClock accumulates the time elapsed between the timestamps of a log.
+// NMEA sentences carry the time of day only, so timestamps are compared as such,
+// which also keeps the clock steady across midnight.
+type replayClock struct {
+	last    time.Duration
+	started bool
+	elapsed time.Duration
+
+	monoStart time.Duration
+	monoSet   bool
+}
+
+// advanceMono moves the clock to the monotonic receive time of a recorded line
+// and returns the elapsed time.
+func (c *replayClock) advanceMono(mono time.Duration) time.Duration {
+	if !c.monoSet {
+		c.monoStart, c.monoSet = mono-c.elapsed, true
+	}
+	if elapsed := mono - c.monoStart; elapsed > c.elapsed {
+		c.elapsed = elapsed
+	}
+	return c.elapsed
+}
+
+// advance moves the clock to the time of day stamp and returns the elapsed time.
+// Timestamps older than the last one, e.g. of a late report, don't move the clock back.
+func (c *replayClock) advance(stamp time.Duration) time.Duration {
+	if !c.started {
+		c.last, c.started = stamp, true
+		return c.elapsed
+	}
+
+	delta := stamp - c.last
+	const day = 24 * time.Hour
+	if delta < -day/2 {
+		delta += day
+	}
+	if delta > 0 {
+		c.elapsed += delta
+		c.last = stamp
+	}
+	return c.elapsed
+}
+
+// recordedEntry returns the entry of a line recorded in the RecordJSONLines format.
+func recordedEntry(line string) (recordEntry, bool) {
+	if !strings.HasPrefix(line, "{") {
+		return recordEntry{}, false
+	}
+	var peek struct {
+		Class string  `json:"class"`
+		Mono  *int64  `json:"mono"`
+		Line  *string `json:"line"`
+	}
+	if err := json.Unmarshal([]byte(line), &peek); err != nil || peek.Class != "" || peek.Mono == nil || peek.Line == nil {
+		return recordEntry{}, false
+	}
+	return recordEntry{Mono: *peek.Mono, Line: *peek.Line}, true
+}
+
+// lineTimestamp returns the UTC time of day of the report or sentence in the line, if it has one.
+func lineTimestamp(line string) (time.Duration, bool) {
+	if strings.HasPrefix(line, "{") {
+		var peek struct {
+			Time string `json:"time"`
+		}
+		if err := json.Unmarshal([]byte(line), &peek); err != nil || peek.Time == "" {
+			return 0, false
+		}
+		t, err := time.Parse(time.RFC3339Nano, peek.Time)
+		if err != nil {
+			return 0, false
+		}
+		t = t.UTC()
+		return t.Sub(t.Truncate(24 * time.Hour)), true
+	}
+
+	sentence, err := nmea.Parse(line)
+	if err != nil {
+		return 0, false
+	}
+	var t nmea.Time
+	switch v := sentence.(type) {
+	case nmea.GGA:
+		t = v.Time
+	case nmea.RMC:
+		t = v.Time
+	case nmea.GLL:
+		t = v.Time
+	case nmea.ZDA:
+		t = v.Time
+	case nmea.GST:
+		t = v.Time
+	case nmea.GBS:
+		t = v.Time
+	}
+	if !t.Valid {
+		return 0, false
+	}
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute +
+		time.Duration(t.Second)*time.Second + time.Duration(t.Millisecond)*time.Millisecond, true
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
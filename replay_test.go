@@ -0,0 +1,119 @@
+package gpsd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeWall is the wall clock of a replay, moved forward by its sleeps only.
+type fakeWall struct {
+	now    time.Time
+	sleeps int
+}
+
+func (w *fakeWall) sleep(_ context.Context, d time.Duration) error {
+	if d > 0 {
+		w.now = w.now.Add(d)
+		w.sleeps++
+	}
+	return nil
+}
+
+func TestReplayer(t *testing.T) {
+	gpspipeW := strings.Join([]string{
+		`{"class":"TPV","mode":3,"time":"2026-01-01T12:00:00.000Z"}`,
+		// Delivered together with the previous report.
+		`{"class":"SKY","satellites":[]}`,
+		`{"class":"TPV","mode":3,"time":"2026-01-01T12:00:01.000Z"}`,
+		// A late report doesn't move the clock back.
+		`{"class":"TPV","mode":3,"time":"2026-01-01T12:00:00.500Z"}`,
+		`{"class":"TPV","mode":3,"time":"2026-01-01T12:00:03.000Z"}`,
+	}, "\n")
+	gpspipeR := strings.Join([]string{
+		"$GPGGA,235959.50,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*60",
+		"$GPRMC,000000.00,A,4807.038,N,01131.000,E,022.4,084.4,020126,003.1,W*41",
+		"$GPGGA,000001.00,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*65",
+	}, "\r\n") + "\r\n"
+	// The receive times of the entries take precedence over the times of the reports.
+	jsonLines := strings.Join([]string{
+		`{"time":"2026-01-01T12:00:00.1Z","mono":5000000000,"line":"{\"class\":\"TPV\",\"mode\":3,\"time\":\"2026-01-01T12:00:00.000Z\"}"}`,
+		`{"time":"2026-01-01T12:00:00.35Z","mono":5250000000,"line":"{\"class\":\"SKY\",\"time\":\"2026-01-01T12:00:09.000Z\"}"}`,
+		`{"time":"2026-01-01T12:00:02.1Z","mono":7000000000,"line":"$GPGGA,235959.50,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*60\r\n"}`,
+	}, "\n") + "\n"
+
+	tests := []struct {
+		name  string
+		log   string
+		speed float64
+		want  []time.Duration
+	}{
+		{name: "gpspipe -w", log: gpspipeW, speed: ReplayRealTime,
+			want: []time.Duration{0, 0, time.Second, time.Second, 3 * time.Second}},
+		{name: "gpspipe -w accelerated", log: gpspipeW, speed: 10,
+			want: []time.Duration{0, 0, 100 * time.Millisecond, 100 * time.Millisecond, 300 * time.Millisecond}},
+		{name: "gpspipe -w as fast as possible", log: gpspipeW, speed: ReplayAsFastAsPossible,
+			want: []time.Duration{0, 0, 0, 0, 0}},
+		{name: "gpspipe -r across midnight", log: gpspipeR, speed: ReplayRealTime,
+			want: []time.Duration{0, 500 * time.Millisecond, 1500 * time.Millisecond}},
+		{name: "gpspipe -r slowed down", log: gpspipeR, speed: 0.5,
+			want: []time.Duration{0, time.Second, 3 * time.Second}},
+		{name: "JSON Lines", log: jsonLines, speed: ReplayRealTime,
+			want: []time.Duration{0, 250 * time.Millisecond, 2 * time.Second}},
+		{name: "JSON Lines as fast as possible", log: jsonLines, speed: ReplayAsFastAsPossible,
+			want: []time.Duration{0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			wall := &fakeWall{now: start}
+			replayer := NewReplayer(strings.NewReader(tt.log))
+			replayer.Speed = tt.speed
+			replayer.now = func() time.Time { return wall.now }
+			replayer.sleep = wall.sleep
+
+			var got []time.Duration
+			deliver := func(interface{}) { got = append(got, wall.now.Sub(start)) }
+			for _, class := range []string{msgClassTPV, msgClassSKY, "--GGA", "--RMC"} {
+				replayer.Session().Subscribe(class, deliver)
+			}
+
+			if err := replayer.Run(context.Background()); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("delivered at %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("delivered at %v, want %v", got, tt.want)
+					break
+				}
+			}
+			if tt.speed == ReplayAsFastAsPossible && wall.sleeps != 0 {
+				t.Errorf("%d sleeps, want none", wall.sleeps)
+			}
+		})
+	}
+}
+
+func TestReplayerCanceled(t *testing.T) {
+	replayer := NewReplayer(strings.NewReader(`{"class":"TPV","time":"2026-01-01T12:00:00.000Z"}` + "\n" +
+		`{"class":"TPV","time":"2026-01-01T12:00:01.000Z"}` + "\n"))
+	ctx, cancel := context.WithCancel(context.Background())
+	replayer.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepContext(ctx, d)
+	}
+	delivered := 0
+	SubscribeFunc(replayer.Session(), func(*TPVReport) { delivered++ })
+
+	if err := replayer.Run(ctx); err != context.Canceled {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	if delivered != 1 {
+		t.Errorf("%d reports delivered, want 1", delivered)
+	}
+}
@@ -1,7 +1,11 @@
 package gpsd
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -9,6 +13,329 @@ type gpsdReport struct {
 	Class string `json:"class"`
 }
 
+/*
+FieldSet records which fields were present in a report received from gpsd
+
+gpsd omits fields it has no data for, and they decode to zero values: a missing "lat"
+is indistinguishable from a fix on the equator. Check the presence before using a value:
+
+	if r.Has("lat") && r.Has("lon") {
+		fmt.Println(r.Lat, r.Lon)
+	}
+*/
+type FieldSet struct {
+	index *fieldIndex
+	bits  [2]uint64
+}
+
+// Has reports whether the field with the given JSON name (e.g. "lat" or "altHAE") was present.
+func (f FieldSet) Has(name string) bool {
+	if f.index == nil {
+		return false
+	}
+	bit, ok := f.index.bits[name]
+	return ok && f.bits[bit/64]&(1<<(bit%64)) != 0
+}
+
+// with returns a copy of the set that also holds the names, leaving f untouched.
+// Names that aren't fields of the report are ignored.
+func (f FieldSet) with(names ...string) FieldSet {
+	if f.index == nil {
+		return f
+	}
+	for _, name := range names {
+		if bit, ok := f.index.bits[name]; ok {
+			f.bits[bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return f
+}
+
+// maxFields is the number of fields a FieldSet can record.
+const maxFields = 128
+
+// fieldIndex maps the JSON names of the fields of a report type to the bits of a FieldSet.
+// It is built once per type and shared by every report of that type.
+type fieldIndex struct {
+	bits map[string]uint
+}
+
+// fieldIndexes caches the *fieldIndex of every report type by its reflect.Type.
+var fieldIndexes sync.Map
+
+// indexOf returns the field index of the struct type t.
+func indexOf(t reflect.Type) *fieldIndex {
+	if idx, ok := fieldIndexes.Load(t); ok {
+		return idx.(*fieldIndex)
+	}
+
+	idx := &fieldIndex{bits: make(map[string]uint)}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if len(idx.bits) == maxFields {
+			panic(fmt.Sprintf("gpsd: %s has more than %d fields", t, maxFields))
+		}
+		idx.bits[name] = uint(len(idx.bits))
+	}
+
+	actual, _ := fieldIndexes.LoadOrStore(t, idx)
+	return actual.(*fieldIndex)
+}
+
+// decodeFields decodes data into v, a pointer to a struct, and records its present top-level fields in fs.
+// The presence is recorded by scanning the keys of data, without decoding it a second time.
+func decodeFields(data []byte, v interface{}, fs *FieldSet) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	idx := indexOf(reflect.TypeOf(v).Elem())
+	set := FieldSet{index: idx}
+	scanObject(data, func(key, value []byte) {
+		// gpsd never sends null, but treat it as absent to be on the safe side.
+		if bit, ok := idx.bits[string(key)]; ok && string(value) != "null" {
+			set.bits[bit/64] |= 1 << (bit % 64)
+		}
+	})
+	*fs = set
+
+	return nil
+}
+
+// scanObject calls f with the raw key and value of every top-level member of the JSON object in data,
+// which must be valid JSON. Keys are passed as they appear, without unescaping.
+func scanObject(data []byte, f func(key, value []byte)) {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return
+	}
+	i++
+	for {
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != '"' {
+			return
+		}
+		end := skipString(data, i)
+		key := data[i+1 : end-1]
+
+		i = skipSpace(data, end)
+		if i >= len(data) || data[i] != ':' {
+			return
+		}
+		i = skipSpace(data, i+1)
+		start := i
+		i = skipValue(data, i)
+		f(key, data[start:i])
+
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != ',' {
+			return
+		}
+		i++
+	}
+}
+
+// skipSpace returns the index of the first non-whitespace byte of data from i.
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\r' || data[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// skipString returns the index following the JSON string starting at data[i].
+func skipString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// skipValue returns the index following the JSON value starting at data[i].
+func skipValue(data []byte, i int) int {
+	depth := 0
+	for ; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			i = skipString(data, i) - 1
+		case '{', '[':
+			depth++
+			continue
+		case '}', ']':
+			// The end of the enclosing object.
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return i
+			}
+			continue
+		default:
+			continue
+		}
+		if depth == 0 {
+			return i + 1
+		}
+	}
+	return i
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *TPVReport) UnmarshalJSON(data []byte) error {
+	type plain TPVReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *SKYReport) UnmarshalJSON(data []byte) error {
+	type plain SKYReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *Satellite) UnmarshalJSON(data []byte) error {
+	type plain Satellite
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *GSTReport) UnmarshalJSON(data []byte) error {
+	type plain GSTReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *ATTReport) UnmarshalJSON(data []byte) error {
+	type plain ATTReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *VERSIONReport) UnmarshalJSON(data []byte) error {
+	type plain VERSIONReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *DEVICESReport) UnmarshalJSON(data []byte) error {
+	type plain DEVICESReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *DEVICEReport) UnmarshalJSON(data []byte) error {
+	type plain DEVICEReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *PPSReport) UnmarshalJSON(data []byte) error {
+	type plain PPSReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *POLLReport) UnmarshalJSON(data []byte) error {
+	type plain POLLReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *WATCHReport) UnmarshalJSON(data []byte) error {
+	type plain WATCHReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *ERRORReport) UnmarshalJSON(data []byte) error {
+	type plain ERRORReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *TOFFReport) UnmarshalJSON(data []byte) error {
+	type plain TOFFReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *OSCReport) UnmarshalJSON(data []byte) error {
+	type plain OSCReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *IMUReport) UnmarshalJSON(data []byte) error {
+	type plain IMUReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *RAWReport) UnmarshalJSON(data []byte) error {
+	type plain RAWReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *RawMeasurement) UnmarshalJSON(data []byte) error {
+	type plain RawMeasurement
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *SUBFRAMEReport) UnmarshalJSON(data []byte) error {
+	type plain SUBFRAMEReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *AISReport) UnmarshalJSON(data []byte) error {
+	type plain AISReport
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *RTCM2Report) UnmarshalJSON(data []byte) error {
+	type plain RTCM2Report
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to record the present fields.
+func (r *RTCM3Report) UnmarshalJSON(data []byte) error {
+	type plain RTCM3Report
+	return decodeFields(data, (*plain)(r), &r.FieldSet)
+}
+
+func (*TPVReport) reportClass() string      { return msgClassTPV }
+func (*SKYReport) reportClass() string      { return msgClassSKY }
+func (*GSTReport) reportClass() string      { return msgClassGST }
+func (*ATTReport) reportClass() string      { return msgClassATT }
+func (*VERSIONReport) reportClass() string  { return msgClassVersion }
+func (*DEVICESReport) reportClass() string  { return msgClassDevices }
+func (*DEVICEReport) reportClass() string   { return msgClassDevice }
+func (*PPSReport) reportClass() string      { return msgClassPPS }
+func (*ERRORReport) reportClass() string    { return msgClassError }
+func (*POLLReport) reportClass() string     { return msgClassPoll }
+func (*WATCHReport) reportClass() string    { return msgClassWatch }
+func (*TOFFReport) reportClass() string     { return msgClassTOFF }
+func (*OSCReport) reportClass() string      { return msgClassOSC }
+func (*IMUReport) reportClass() string      { return msgClassIMU }
+func (*RAWReport) reportClass() string      { return msgClassRAW }
+func (*SUBFRAMEReport) reportClass() string { return msgClassSubframe }
+func (*AISReport) reportClass() string      { return msgClassAIS }
+func (*RTCM2Report) reportClass() string    { return msgClassRTCM2 }
+func (*RTCM3Report) reportClass() string    { return msgClassRTCM3 }
+
 /*
 TPVReport is a Time-Position-Velocity report
 
@@ -30,38 +357,71 @@ example:
 		"lat":46.498293369,"lon":7.567411672,"alt":1343.127,
 		"eph":36.000,"epv":32.321,
 		"track":10.3788,"speed":0.091,"climb":-0.085,"mode":3}
+
+	{"class":"TPV","device":"/dev/ttyACM0","mode":3,"status":3,
+		"time":"2024-05-10T08:12:35.000Z","leapseconds":18,"ept":0.005,
+		"lat":50.450100000,"lon":30.523400000,"altHAE":220.123,"altMSL":190.456,
+		"geoidSep":29.667,"eph":0.014,"epv":0.021,"sep":0.025,
+		"track":12.3,"magtrack":6.1,"magvar":6.2,"speed":0.012,"climb":0.001,
+		"velN":0.011,"velE":0.002,"velD":-0.001,
+		"ecefx":3157023.12,"ecefy":1864217.54,"ecefz":4889785.33,"ecefpAcc":0.03}
 */
 type TPVReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	// Fixed: "TPV"
 	Class string `json:"class"`
-	// todo: add status
 	// todo: find out where tag is from and document
 	Tag string `json:"tag"`
 	// Name of the originating device.
 	Device string `json:"device"`
 	// NMEA mode: %d, 0=no mode value yet seen, 1=no fix, 2=2D, 3=3D.
 	Mode Mode `json:"mode"`
+	// GPS fix status. Absent (Unknown) means a normal GPS fix.
+	Status FixStatus `json:"status"`
 	// Time/date stamp in ISO8601 format, UTC. May have a fractional part of up to .001sec precision.
 	// May be absent if the mode is not 2D or 3D.
 	Time time.Time `json:"time"`
-	// todo: update for altHAE and alt MSL
+	// Current leap seconds.
+	Leapseconds int `json:"leapseconds"`
 	// Estimated timestamp error in seconds. Certainty unknown.
 	Ept float64 `json:"ept"`
 	// Latitude in degrees: +/- signifies North/South.
 	Lat float64 `json:"lat"`
 	// Longitude in degrees: +/- signifies East/West.
 	Lon float64 `json:"lon"`
-	// Longitude error estimate in meters. Certainty unknown.
-	// Deprecated. Undefined. Use altHAE or altMSL.
+	// Altitude in meters.
+	// Deprecated: Undefined. Use AltHAE or AltMSL.
 	Alt float64 `json:"alt"`
+	// Altitude, height above ellipsoid, in meters. Probably WGS84.
+	AltHAE float64 `json:"altHAE"`
+	// MSL Altitude in meters. The geoid used is rarely specified and is often inaccurate.
+	AltMSL float64 `json:"altMSL"`
+	// Geoid separation is the difference between the WGS84 reference ellipsoid and the geoid
+	// (Mean Sea Level) in meters. Almost no GNSS receiver specifies how they compute their geoid.
+	GeoidSep float64 `json:"geoidSep"`
+	// Datum. Often WGS84.
+	Datum string `json:"datum"`
 	// Longitude error estimate in meters. Certainty unknown.
 	Epx float64 `json:"epx"`
 	// Latitude error estimate in meters. Certainty unknown.
 	Epy float64 `json:"epy"`
 	// Estimated vertical error in meters. Certainty unknown.
 	Epv float64 `json:"epv"`
+	// Estimated horizontal Position (2D) Error in meters. Also known as Estimated Position Error (epe).
+	// Certainty unknown.
+	Eph float64 `json:"eph"`
+	// Estimated Spherical (3D) Position Error in meters. Guessed to be 95% confidence,
+	// but many GNSS receivers do not specify, so certainty unknown.
+	Sep float64 `json:"sep"`
 	// Course over ground, degrees from true north.
 	Track float64 `json:"track"`
+	// Course over ground, degrees magnetic.
+	Magtrack float64 `json:"magtrack"`
+	// Magnetic variation, degrees. Also known as the magnetic declination (the direction of
+	// the horizontal component of the magnetic field measured clockwise from north) in degrees,
+	// Positive is West variation. Negative is East variation.
+	Magvar float64 `json:"magvar"`
 	// Speed over ground, meters per second.
 	Speed float64 `json:"speed"`
 	// Climb (positive) or sink (negative) rate, meters per second.
@@ -72,6 +432,64 @@ type TPVReport struct {
 	Eps float64 `json:"eps"`
 	// Estimated climb error in meters per second. Certainty unknown.
 	Epc float64 `json:"epc"`
+	// North velocity component in meters per second.
+	VelN float64 `json:"velN"`
+	// East velocity component in meters per second.
+	VelE float64 `json:"velE"`
+	// Down velocity component in meters per second.
+	VelD float64 `json:"velD"`
+	// ECEF X position in meters.
+	ECEFX float64 `json:"ecefx"`
+	// ECEF Y position in meters.
+	ECEFY float64 `json:"ecefy"`
+	// ECEF Z position in meters.
+	ECEFZ float64 `json:"ecefz"`
+	// ECEF position error in meters. Certainty unknown.
+	ECEFPAcc float64 `json:"ecefpAcc"`
+	// ECEF X velocity in meters per second.
+	ECEFVX float64 `json:"ecefvx"`
+	// ECEF Y velocity in meters per second.
+	ECEFVY float64 `json:"ecefvy"`
+	// ECEF Z velocity in meters per second.
+	ECEFVZ float64 `json:"ecefvz"`
+	// ECEF velocity error in meters per second. Certainty unknown.
+	ECEFVAcc float64 `json:"ecefvAcc"`
+	// Down component of relative position vector in meters (RTK).
+	RelD float64 `json:"relD"`
+	// East component of relative position vector in meters (RTK).
+	RelE float64 `json:"relE"`
+	// North component of relative position vector in meters (RTK).
+	RelN float64 `json:"relN"`
+	// Age of DGPS data. In seconds.
+	DGPSAge float64 `json:"dgpsAge"`
+	// Station of DGPS data.
+	DGPSSta int `json:"dgpsSta"`
+	// Receiver clock bias in nanoseconds.
+	ClockBias int64 `json:"clockbias"`
+	// Receiver clock drift in nanoseconds per second.
+	ClockDrift int64 `json:"clockdrift"`
+	// Antenna status: 0 unknown, 1 OK, 2 open circuit, 3 short circuit.
+	Ant int `json:"ant"`
+	// Antenna power supply voltage in volts.
+	AntPwr float64 `json:"antPwr"`
+	// Jamming indicator: 0 (no jamming) to 255 (severe jamming). -1 means unknown.
+	Jam int `json:"jam"`
+	// Receiver temperature in degrees Celsius.
+	Temp float64 `json:"temp"`
+	// Water depth in meters.
+	Depth float64 `json:"depth"`
+	// Water temperature in degrees Celsius.
+	WTemp float64 `json:"wtemp"`
+	// Wind angle magnetic in degrees.
+	WAngleM float64 `json:"wanglem"`
+	// Wind angle relative in degrees.
+	WAngleR float64 `json:"wangler"`
+	// Wind angle true in degrees.
+	WAngleT float64 `json:"wanglet"`
+	// Wind speed relative in meters per second.
+	WSpeedR float64 `json:"wspeedr"`
+	// Wind speed true in meters per second.
+	WSpeedT float64 `json:"wspeedt"`
 }
 
 /*
@@ -102,8 +520,17 @@ example:
 			{"PRN":4,"el":15,"az":199,"ss":36,"used":true},
 			{"PRN":2,"el":34,"az":241,"ss":43,"used":true},
 			{"PRN":27,"el":71,"az":76,"ss":43,"used":true}]}
+
+	{"class":"SKY","device":"/dev/ttyACM0","time":"2024-05-10T08:12:35.000Z",
+		"nSat":3,"uSat":2,"hdop":0.61,"pdop":1.12,
+		"satellites":[
+			{"PRN":5,"gnssid":0,"svid":5,"el":44.0,"az":103.0,"ss":47.0,"used":true,"health":1},
+			{"PRN":304,"gnssid":2,"svid":4,"sigid":1,"el":28.0,"az":245.0,"ss":38.0,"used":true,"health":1},
+			{"PRN":72,"gnssid":6,"svid":8,"freqid":13,"el":9.0,"az":12.0,"ss":0.0,"used":false,"health":1}]}
 */
 type SKYReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	// Fixed: "SKY"
 	Class string `json:"class"`
 	// todo: find out where tag is from and document
@@ -133,14 +560,51 @@ type SKYReport struct {
 	// Geometric (hyperspherical) dilution of precision, a combination of PDOP and TDOP.
 	// A dimensionless factor which should be multiplied by a base UERE to get an error estimate.
 	Gdop float64 `json:"gdop"`
+	// Number of satellite objects in "satellites" array.
+	NSat int `json:"nSat"`
+	// Number of satellites used in navigation solution.
+	USat int `json:"uSat"`
+	// Pseudorange residual in meters.
+	PrRes float64 `json:"prRes"`
+	// Quality indicator, see Satellite.Qual.
+	Qual int `json:"qual"`
 	// List of satellite objects in skyview
 	Satellites []Satellite `json:"satellites"`
 }
 
+// UsedCount returns the number of satellites used in the navigation solution.
+func (r *SKYReport) UsedCount() int {
+	if r.Has("uSat") {
+		return r.USat
+	}
+
+	n := 0
+	for _, sat := range r.Satellites {
+		if sat.Used {
+			n++
+		}
+	}
+	return n
+}
+
+// ByConstellation groups the satellites in skyview by their constellation.
+// Satellites reported without "gnssid" are grouped by their PRN, see Satellite.Constellation.
+func (r *SKYReport) ByConstellation() map[Constellation][]Satellite {
+	groups := make(map[Constellation][]Satellite)
+	for _, sat := range r.Satellites {
+		c := sat.Constellation()
+		groups[c] = append(groups[c], sat)
+	}
+	return groups
+}
+
 // Satellite describes a location of a GPS satellite
 type Satellite struct {
-	// PRN ID of the satellite. 1-63 are GNSS satellites, 64-96 are GLONASS satellites, 100-164 are SBAS satellites
-	PRN float64 `json:"PRN"`
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// PRN ID of the satellite. 1-63 are GNSS satellites, 64-96 are GLONASS satellites, 100-164 are SBAS satellites.
+	// The numbering is ambiguous across constellations, prefer GNSSID and SVID when present.
+	PRN int `json:"PRN"`
 	// Azimuth, degrees from true north.
 	Az float64 `json:"az"`
 	// Elevation in degrees.
@@ -150,6 +614,98 @@ type Satellite struct {
 	// Used in current solution? (SBAS/WAAS/EGNOS satellites may be flagged used if the solution
 	//  has corrections from them, but not all drivers make this information available.)
 	Used bool `json:"used"`
+	// The GNSS ID, as defined by u-blox, not NMEA.
+	GNSSID Constellation `json:"gnssid"`
+	// The satellite ID (PRN) within its constellation. As defined by u-blox, not NMEA.
+	SVID int `json:"svid"`
+	// The signal ID of this signal. As defined by u-blox, not NMEA.
+	SigID int `json:"sigid"`
+	// For GLONASS satellites only: the frequency ID of the signal. As defined by u-blox, range 0 to 13.
+	// The freqid is the frequency slot plus 7.
+	FreqID int `json:"freqid"`
+	// The health of this satellite. 0 is unknown, 1 is OK, and 2 is unhealthy.
+	Health int `json:"health"`
+	// Quality indicator: 0 no signal, 1 searching signal, 2 signal acquired, 3 signal detected but unusable,
+	// 4 code locked and time synchronized, 5-7 code and carrier locked and time synchronized.
+	Qual int `json:"qual"`
+	// Pseudorange in meters.
+	Pr float64 `json:"pr"`
+	// Pseudorange rate of change (Doppler) in meters per second.
+	PrRate float64 `json:"prRate"`
+	// Pseudorange residual in meters.
+	PrRes float64 `json:"prRes"`
+}
+
+// Constellation returns the constellation of the satellite: GNSSID if present,
+// otherwise a guess based on the PRN numbering used by gpsd.
+func (s Satellite) Constellation() Constellation {
+	if s.Has("gnssid") {
+		return s.GNSSID
+	}
+
+	switch {
+	case s.PRN >= 1 && s.PRN <= 63:
+		return GPS
+	case s.PRN >= 64 && s.PRN <= 96:
+		return GLONASS
+	case s.PRN >= 100 && s.PRN <= 164:
+		return SBAS
+	case s.PRN >= 173 && s.PRN <= 182:
+		return IMES
+	// gpsd documents QZSS as 193-202 and BeiDou as 201-235 (besides 401-437):
+	// the ambiguous 201 and 202 are taken as BeiDou.
+	case s.PRN >= 193 && s.PRN <= 200:
+		return QZSS
+	case s.PRN >= 201 && s.PRN <= 235:
+		return BeiDou
+	case s.PRN >= 301 && s.PRN <= 336:
+		return Galileo
+	case s.PRN >= 401 && s.PRN <= 437:
+		return BeiDou
+	default:
+		return UnknownConstellation
+	}
+}
+
+// Constellation is a GNSS identifier, as defined by u-blox and used by gpsd in the "gnssid" field.
+type Constellation int
+
+const (
+	GPS     Constellation = 0
+	SBAS    Constellation = 1
+	Galileo Constellation = 2
+	BeiDou  Constellation = 3
+	IMES    Constellation = 4
+	QZSS    Constellation = 5
+	GLONASS Constellation = 6
+	NavIC   Constellation = 7
+
+	// UnknownConstellation is returned when the constellation can't be determined.
+	UnknownConstellation Constellation = -1
+)
+
+// String implements fmt.Stringer interface.
+func (c Constellation) String() string {
+	switch c {
+	case GPS:
+		return "GPS"
+	case SBAS:
+		return "SBAS"
+	case Galileo:
+		return "Galileo"
+	case BeiDou:
+		return "BeiDou"
+	case IMES:
+		return "IMES"
+	case QZSS:
+		return "QZSS"
+	case GLONASS:
+		return "GLONASS"
+	case NavIC:
+		return "NavIC"
+	default:
+		return "unknown"
+	}
 }
 
 /*
@@ -165,6 +721,8 @@ example:
 			"lat":1.600,"lon":1.200,"alt":2.520}
 */
 type GSTReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	// Fixed: "GST"
 	Class string `json:"class"`
 	// todo: find out where tag is from and document
@@ -221,6 +779,8 @@ example:
 		"dip":13641.000,"mag_x":2454.000}
 */
 type ATTReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	// Fixed: "ATT"
 	Class string `json:"class"`
 	// todo: find out where tag is from and document
@@ -288,6 +848,8 @@ example:
 	}
 */
 type VERSIONReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	Class      string `json:"class"`
 	Release    string `json:"release"`
 	Rev        string `json:"rev"`
@@ -307,17 +869,31 @@ example:
 		{"class":"DEVICE","path":"/dev/pts/1","flags":1,"driver":"SiRF binary"},
 		{"class":"DEVICE","path":"/dev/pts/3","flags":4,"driver":"AIVDM"}]}
 
-The daemon occasionally ships a bare DEVICE object to the client (that is, one not inside a DEVICES wrapper).
-The data content of these objects will be described later as a response to the ?DEVICE command.
+The daemon occasionally ships a bare DEVICE object to the client (that is, one not inside a DEVICES wrapper),
+e.g. when a device is activated or deactivated. It is delivered to subscribers as DEVICEReport.
 */
 type DEVICESReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	Class   string         `json:"class"`
 	Devices []DEVICEReport `json:"devices"`
 	Remote  string         `json:"remote"`
 }
 
-// DEVICEReport reports a state of a particular device
+/*
+DEVICEReport reports a state of a particular device
+
+response to "?DEVICE" command, also sent unsolicited when a device is activated or deactivated.
+
+example:
+
+	{"class":"DEVICE","path":"/dev/ttyUSB0","activated":"2024-06-13T09:14:29.128Z",
+		"flags":1,"driver":"u-blox","subtype":"SW 2.01 (75331),HW 00080000",
+		"bps":9600,"parity":"N","stopbits":1,"native":1,"cycle":1.00,"mincycle":0.25}
+*/
 type DEVICEReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	Class     string  `json:"class"`
 	Path      string  `json:"path"`
 	Activated string  `json:"activated"`
@@ -342,22 +918,524 @@ func (d DEVICEReport) String() string {
 	)
 }
 
-// PPSReport is triggered on each pulse-per-second strobe from a device
+/*
+PPSReport is triggered on each pulse-per-second strobe from a device
+
+It reports the time of the pulse as derived from the GPS (real) and as seen by
+the system clock at the moment the pulse was caught. It is emitted only when PPS
+is enabled in the WATCH options.
+
+example:
+
+	{"class":"PPS","device":"/dev/ttyUSB0",
+		"real_sec":1330212592, "real_nsec":0,
+		"clock_sec":1330212592,"clock_nsec":343184,
+		"precision":-20,"shm":"NTP2","qErr":-12}
+*/
 type PPSReport struct {
-	Class      string  `json:"class"`
-	Device     string  `json:"device"`
-	RealSec    float64 `json:"real_sec"`
-	RealMusec  float64 `json:"real_musec"`
-	ClockSec   float64 `json:"clock_sec"`
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "PPS"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Seconds from the PPS source.
+	RealSec int64 `json:"real_sec"`
+	// Nanoseconds from the PPS source.
+	RealNsec int64 `json:"real_nsec"`
+	// Seconds from the system clock.
+	ClockSec int64 `json:"clock_sec"`
+	// Nanoseconds from the system clock.
+	ClockNsec int64 `json:"clock_nsec"`
+	// NTP style estimate of PPS precision.
+	Precision int `json:"precision"`
+	// Shared memory segment name, e.g. "NTP2".
+	SHM string `json:"shm"`
+	// Quantization error of the PPS, in picoseconds. Sometimes called the "sawtooth" error.
+	QErr int64 `json:"qErr"`
+	// Microseconds from the PPS source.
+	// Deprecated: sent by gpsd before protocol 3.9, use RealNsec.
+	RealMusec float64 `json:"real_musec"`
+	// Microseconds from the system clock.
+	// Deprecated: sent by gpsd before protocol 3.9, use ClockNsec.
 	ClockMusec float64 `json:"clock_musec"`
 }
 
+// RealTime returns the time of the pulse according to the PPS source.
+func (r *PPSReport) RealTime() time.Time {
+	if !r.Has("real_nsec") && r.Has("real_musec") {
+		return time.Unix(r.RealSec, int64(r.RealMusec*1e3))
+	}
+	return time.Unix(r.RealSec, r.RealNsec)
+}
+
+// ClockTime returns the time of the pulse according to the system clock.
+func (r *PPSReport) ClockTime() time.Time {
+	if !r.Has("clock_nsec") && r.Has("clock_musec") {
+		return time.Unix(r.ClockSec, int64(r.ClockMusec*1e3))
+	}
+	return time.Unix(r.ClockSec, r.ClockNsec)
+}
+
+// Offset returns the offset of the PPS source relative to the system clock, i.e. RealTime minus ClockTime.
+// A positive offset means that the system clock is behind.
+func (r *PPSReport) Offset() time.Duration {
+	return r.RealTime().Sub(r.ClockTime())
+}
+
+/*
+TOFFReport reports the time offset of a fix
+
+It is emitted on each cycle when PPS is enabled in the WATCH options and reports the
+GPS time as derived from the GPS serial data stream, compared to the system clock
+at the moment that data was received.
+
+example:
+
+	{"class":"TOFF","device":"/dev/ttyUSB0",
+		"real_sec":1330212592, "real_nsec":343182,
+		"clock_sec":1330212592,"clock_nsec":343184,
+		"precision":-2}
+*/
+type TOFFReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "TOFF"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Seconds from the GPS clock.
+	RealSec int64 `json:"real_sec"`
+	// Nanoseconds from the GPS clock.
+	RealNsec int64 `json:"real_nsec"`
+	// Seconds from the system clock.
+	ClockSec int64 `json:"clock_sec"`
+	// Nanoseconds from the system clock.
+	ClockNsec int64 `json:"clock_nsec"`
+	// NTP style estimate of PPS precision.
+	Precision int `json:"precision"`
+}
+
+// RealTime returns the time of the fix according to the GPS serial data stream.
+func (r *TOFFReport) RealTime() time.Time {
+	return time.Unix(r.RealSec, r.RealNsec)
+}
+
+// ClockTime returns the time according to the system clock at the moment the data was received.
+func (r *TOFFReport) ClockTime() time.Time {
+	return time.Unix(r.ClockSec, r.ClockNsec)
+}
+
+// Offset returns the offset of the GPS time relative to the system clock, i.e. RealTime minus ClockTime.
+// A positive offset means that the system clock is behind.
+func (r *TOFFReport) Offset() time.Duration {
+	return r.RealTime().Sub(r.ClockTime())
+}
+
+/*
+OSCReport reports the status of a GPS-disciplined oscillator (GPSDO)
+
+example:
+
+	{"class":"OSC","running":true,"device":"/dev/ttyUSB0",
+		"reference":true,"disciplined":true,"delta":67}
+*/
+type OSCReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "OSC"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// If true, the oscillator is currently running. Oscillators may require warm-up time at the start of the day.
+	Running bool `json:"running"`
+	// If true, the oscillator is receiving a GPS PPS signal.
+	Reference bool `json:"reference"`
+	// If true, the GPS PPS signal is sufficiently stable and is being used to discipline the local oscillator.
+	Disciplined bool `json:"disciplined"`
+	// The time difference (in nanoseconds) between the GPS-disciplined oscillator PPS output pulse
+	// and the most recent GPS PPS input pulse.
+	Delta int64 `json:"delta"`
+}
+
+/*
+IMUReport is an inertial measurement unit report
+
+It is returned by IMUs and gyroscopes. Older gpsd versions reported these values as part of ATT.
+
+example:
+
+	{"class":"IMU","device":"/dev/ttyACM0","time":"2024-05-10T08:12:35.000Z",
+		"timeTag":1245000,"acc_x":0.12,"acc_y":-0.05,"acc_z":9.81,
+		"gyro_x":0.01,"gyro_y":0.02,"gyro_z":-0.01,"gyro_temp":31.5}
+*/
+type IMUReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "IMU"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Time/date stamp in ISO8601 format, UTC. May have a fractional part of up to .001sec precision.
+	Time time.Time `json:"time"`
+	// Arbitrary time tag of measurement.
+	TimeTag int64 `json:"timeTag"`
+	// Heading, degrees from true north.
+	Heading float64 `json:"heading"`
+	// Pitch in degrees.
+	Pitch float64 `json:"pitch"`
+	// Roll in degrees.
+	Roll float64 `json:"roll"`
+	// Yaw in degrees.
+	Yaw float64 `json:"yaw"`
+	// Scalar acceleration.
+	AccLen float64 `json:"acc_len"`
+	// X component of acceleration.
+	AccX float64 `json:"acc_x"`
+	// Y component of acceleration.
+	AccY float64 `json:"acc_y"`
+	// Z component of acceleration.
+	AccZ float64 `json:"acc_z"`
+	// Temperature at the gyroscope, degrees centigrade.
+	GyroTemp float64 `json:"gyro_temp"`
+	// X component of angular rate, degrees per second.
+	GyroX float64 `json:"gyro_x"`
+	// Y component of angular rate, degrees per second.
+	GyroY float64 `json:"gyro_y"`
+	// Z component of angular rate, degrees per second.
+	GyroZ float64 `json:"gyro_z"`
+	// Scalar magnetic field strength.
+	MagLen float64 `json:"mag_len"`
+	// X component of magnetic field strength.
+	MagX float64 `json:"mag_x"`
+	// Y component of magnetic field strength.
+	MagY float64 `json:"mag_y"`
+	// Z component of magnetic field strength.
+	MagZ float64 `json:"mag_z"`
+	// Temperature at the sensor, degrees centigrade.
+	Temperature float64 `json:"temp"`
+}
+
+/*
+RAWReport contains raw GNSS measurements (pseudoranges, carrier phases, doppler)
+
+example:
+
+	{"class":"RAW","device":"/dev/ttyACM0","time":1715328755,"nsec":0,
+		"rawdata":[
+			{"gnssid":0,"svid":5,"snr":47,"obs":"C1C","lli":0,"locktime":64500,
+				"carrierphase":118794562.416,"pseudorange":22605843.21,"doppler":-1234.567},
+			{"gnssid":2,"svid":4,"sigid":1,"snr":38,"obs":"C1C","lli":0,"locktime":23000,
+				"carrierphase":125413907.012,"pseudorange":23865301.77,"doppler":456.789}]}
+*/
+type RAWReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "RAW"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Seconds since the Unix epoch, UTC. May have a fractional part.
+	Time float64 `json:"time"`
+	// Nanoseconds since the "time" value.
+	Nsec int64 `json:"nsec"`
+	// List of raw measurements.
+	RawData []RawMeasurement `json:"rawdata"`
+}
+
+// RawMeasurement is a single raw measurement of one signal of a satellite.
+type RawMeasurement struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// The GNSS ID, as defined by u-blox, not NMEA.
+	GNSSID Constellation `json:"gnssid"`
+	// The satellite ID within its constellation.
+	SVID int `json:"svid"`
+	// The signal ID of this signal. As defined by u-blox, not NMEA.
+	SigID int `json:"sigid"`
+	// For GLONASS satellites only: the frequency ID of the signal.
+	FreqID int `json:"freqid"`
+	// The RINEX observation code for the signal, e.g. "C1C".
+	Obs string `json:"obs"`
+	// Signal to noise ratio in dBHz.
+	SNR float64 `json:"snr"`
+	// Loss of lock indicator (RINEX definition).
+	LLI int `json:"lli"`
+	// How long the signal has been locked, in milliseconds.
+	LockTime int64 `json:"locktime"`
+	// Carrier phase in cycles.
+	CarrierPhase float64 `json:"carrierphase"`
+	// Pseudorange in meters.
+	Pseudorange float64 `json:"pseudorange"`
+	// Doppler in Hz.
+	Doppler float64 `json:"doppler"`
+	// Code to carrier divergence in meters.
+	C2C float64 `json:"c2c"`
+	// L2 carrier phase in cycles.
+	L2C float64 `json:"l2c"`
+}
+
+/*
+SUBFRAMEReport contains a decoded GPS navigation message subframe
+
+Only one of the page fields is present in a report, depending on the subframe and page.
+
+example:
+
+	{"class":"SUBFRAME","device":"/dev/ttyUSB0","tSV":7,"TOW17":277239,"frame":1,"scaled":true,
+		"EPHEM1":{"WN":2226,"IODC":21,"L2":1,"ura":0,"hlth":0,"L2P":0,"Tgd":-1.1e-08,
+			"toc":259200,"af2":0.0,"af1":-1.1e-12,"af0":1.7e-04}}
+*/
+type SUBFRAMEReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "SUBFRAME"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Transmitting satellite ID.
+	TSV int `json:"tSV"`
+	// 17 MSBs of the 19 LSBs of the GPS time of week.
+	TOW17 int64 `json:"TOW17"`
+	// Subframe ID.
+	Frame int `json:"frame"`
+	// Whether the values are scaled to engineering units.
+	Scaled bool `json:"scaled"`
+	// Subframe 1: clock and health data.
+	Ephem1 *Ephemeris1 `json:"EPHEM1"`
+	// Subframe 2: first part of the ephemeris.
+	Ephem2 *Ephemeris2 `json:"EPHEM2"`
+	// Subframe 3: second part of the ephemeris.
+	Ephem3 *Ephemeris3 `json:"EPHEM3"`
+	// Subframes 4 and 5: almanac data.
+	Almanac *Almanac `json:"ALMANAC"`
+	// Subframe 4 page 18: ionospheric and UTC data.
+	Iono *IonoUTC `json:"IONO"`
+	// Subframe 4 page 25 and subframe 5 page 25: satellite health, left undecoded.
+	Health json.RawMessage `json:"HEALTH"`
+	// Subframe 5 page 25: satellite health, left undecoded.
+	Health2 json.RawMessage `json:"HEALTH2"`
+	// Subframe 4 page 13: estimated range deviations, left undecoded.
+	ERD json.RawMessage `json:"ERD"`
+	// Subframe 4 page 17: special message, left undecoded.
+	Special json.RawMessage `json:"SPECIAL"`
+}
+
+// Ephemeris1 is the content of subframe 1 of the GPS navigation message.
+type Ephemeris1 struct {
+	WN   int     `json:"WN"`
+	IODC int     `json:"IODC"`
+	L2   int     `json:"L2"`
+	URA  float64 `json:"ura"`
+	Hlth int     `json:"hlth"`
+	L2P  int     `json:"L2P"`
+	Tgd  float64 `json:"Tgd"`
+	Toc  float64 `json:"toc"`
+	Af2  float64 `json:"af2"`
+	Af1  float64 `json:"af1"`
+	Af0  float64 `json:"af0"`
+}
+
+// Ephemeris2 is the content of subframe 2 of the GPS navigation message.
+type Ephemeris2 struct {
+	IODE   int     `json:"IODE"`
+	Crs    float64 `json:"Crs"`
+	DeltaN float64 `json:"deltan"`
+	M0     float64 `json:"M0"`
+	Cuc    float64 `json:"Cuc"`
+	E      float64 `json:"e"`
+	Cus    float64 `json:"Cus"`
+	SqrtA  float64 `json:"sqrtA"`
+	Toe    float64 `json:"toe"`
+	FIT    int     `json:"FIT"`
+	AODO   int     `json:"AODO"`
+}
+
+// Ephemeris3 is the content of subframe 3 of the GPS navigation message.
+type Ephemeris3 struct {
+	IODE   int     `json:"IODE"`
+	IDOT   float64 `json:"IDOT"`
+	Cic    float64 `json:"Cic"`
+	Omega0 float64 `json:"Omega0"`
+	Cis    float64 `json:"Cis"`
+	I0     float64 `json:"i0"`
+	Crc    float64 `json:"Crc"`
+	Omega  float64 `json:"omega"`
+	Omegad float64 `json:"Omegad"`
+}
+
+// Almanac is the almanac data of a satellite from subframes 4 and 5 of the GPS navigation message.
+type Almanac struct {
+	ID     int     `json:"ID"`
+	Health int     `json:"Health"`
+	E      float64 `json:"e"`
+	Toa    float64 `json:"toa"`
+	DeltaI float64 `json:"deltai"`
+	Omegad float64 `json:"Omegad"`
+	SqrtA  float64 `json:"sqrtA"`
+	Omega0 float64 `json:"Omega0"`
+	Omega  float64 `json:"omega"`
+	M0     float64 `json:"M0"`
+	Af0    float64 `json:"af0"`
+	Af1    float64 `json:"af1"`
+}
+
+// IonoUTC is the ionospheric and UTC data from subframe 4 page 18 of the GPS navigation message.
+type IonoUTC struct {
+	A0    float64 `json:"a0"`
+	A1    float64 `json:"a1"`
+	A2    float64 `json:"a2"`
+	A3    float64 `json:"a3"`
+	B0    float64 `json:"b0"`
+	B1    float64 `json:"b1"`
+	B2    float64 `json:"b2"`
+	B3    float64 `json:"b3"`
+	UTCA1 float64 `json:"A1"`
+	UTCA0 float64 `json:"A0"`
+	Tot   float64 `json:"tot"`
+	WNt   int     `json:"WNt"`
+	Ls    int     `json:"ls"`
+	WNlsf int     `json:"WNlsf"`
+	DN    int     `json:"DN"`
+	Lsf   int     `json:"lsf"`
+}
+
+/*
+POLLReport is a response to the "?POLL" command
+
+It contains the last-seen fixes of all active devices: one TPV, GST and SKY object
+per device. Devices have to be activated by a WATCH command before they can be polled.
+
+example:
+
+	{"class":"POLL","time":"2010-06-04T10:31:00.289Z","active":1,
+		"tpv":[{"class":"TPV","device":"/dev/ttyUSB0",
+			"time":"2010-09-08T13:33:06.095Z",
+			"ept":0.005,"lat":40.035093060,
+			"lon":-75.519748733,"track":99.4319,"speed":0.123,"mode":2}],
+		"gst":[{"class":"GST","device":"/dev/ttyUSB0",
+			"time":"2010-12-07T10:23:07.096Z","rms":2.440,
+			"major":1.660,"minor":1.120,"orient":68.989,
+			"lat":1.600,"lon":1.200,"alt":2.520}],
+		"sky":[{"class":"SKY","device":"/dev/ttyUSB0",
+			"time":"2010-09-08T13:33:06.095Z","hdop":1.24,"satellites":[]}]}
+*/
+type POLLReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "POLL"
+	Class string `json:"class"`
+	// Time/date stamp in ISO8601 format, UTC. May have a fractional part of up to .001sec precision.
+	Time time.Time `json:"time"`
+	// Count of active devices.
+	Active int `json:"active"`
+	// Last-seen TPV report of every active device.
+	TPV []TPVReport `json:"tpv"`
+	// Last-seen GST report of every active device.
+	GST []GSTReport `json:"gst"`
+	// Last-seen SKY report of every active device.
+	SKY []SKYReport `json:"sky"`
+}
+
+/*
+WATCHReport reports the watcher policy of the session
+
+response to "?WATCH" command
+
+example:
+
+	{"class":"WATCH","enable":true,"json":true,"nmea":false,"raw":0,
+		"scaled":false,"timing":false,"split24":false,"pps":false}
+*/
+type WATCHReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "WATCH"
+	Class string `json:"class"`
+	// Whether watcher mode is enabled.
+	Enable bool `json:"enable"`
+	// Whether JSON reports are dumped.
+	JSON bool `json:"json"`
+	// Whether binary packets are dumped as pseudo-NMEA.
+	NMEA bool `json:"nmea"`
+	// Raw mode: 0 disabled, 1 binary packets as hex, 2 binary packets as they are.
+	Raw int `json:"raw"`
+	// Whether scaling divisors are applied to the output.
+	Scaled bool `json:"scaled"`
+	// Whether AIS type24 sentence parts are aggregated.
+	Split24 bool `json:"split24"`
+	// Whether TOFF and PPS messages are emitted.
+	PPS bool `json:"pps"`
+	// Whether timing information is reported.
+	Timing bool `json:"timing"`
+	// Device being watched, empty if all devices are watched.
+	Device string `json:"device"`
+	// URL of the remote daemon reporting the watch set.
+	Remote string `json:"remote"`
+}
+
 // ERRORReport is an error response
 type ERRORReport struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
 	Class   string `json:"class"`
 	Message string `json:"message"`
 }
 
+// FixStatus describes the quality of the fix of a TPV report.
+type FixStatus byte
+
+const (
+	// StatusUnknown indicates that the status is not reported, which means a normal GPS fix.
+	StatusUnknown FixStatus = 0
+	// StatusNormal indicates a normal GPS fix without DGPS.
+	StatusNormal FixStatus = 1
+	// StatusDGPS indicates a differential GPS fix.
+	StatusDGPS FixStatus = 2
+	// StatusRTKFixed indicates an RTK fix with fixed integer ambiguities.
+	StatusRTKFixed FixStatus = 3
+	// StatusRTKFloat indicates an RTK fix with floating ambiguities.
+	StatusRTKFloat FixStatus = 4
+	// StatusDR indicates a dead reckoning only fix.
+	StatusDR FixStatus = 5
+	// StatusGNSSDR indicates a combined GNSS and dead reckoning fix.
+	StatusGNSSDR FixStatus = 6
+	// StatusTime indicates a time only fix (surveyed position).
+	StatusTime FixStatus = 7
+	// StatusSimulated indicates a simulated fix.
+	StatusSimulated FixStatus = 8
+	// StatusPY indicates a P(Y) code fix.
+	StatusPY FixStatus = 9
+)
+
+// String implements fmt.Stringer interface.
+func (s FixStatus) String() string {
+	switch s {
+	case StatusUnknown:
+		return "unknown"
+	case StatusNormal:
+		return "GPS"
+	case StatusDGPS:
+		return "DGPS"
+	case StatusRTKFixed:
+		return "RTK fixed"
+	case StatusRTKFloat:
+		return "RTK float"
+	case StatusDR:
+		return "DR"
+	case StatusGNSSDR:
+		return "GNSS+DR"
+	case StatusTime:
+		return "time"
+	case StatusSimulated:
+		return "simulated"
+	case StatusPY:
+		return "P(Y)"
+	default:
+		return fmt.Sprintf("FixStatus(%d)", byte(s))
+	}
+}
+
 // Mode describes status of a TPV report
 type Mode byte
 
@@ -0,0 +1,202 @@
+package gpsd
+
+import (
+	"testing"
+	"time"
+)
+
+// testReport decodes the line as a report of the class, like the session does.
+func testReport[T Report](t *testing.T, line string) T {
+	t.Helper()
+	var zero T
+	r, err := unmarshalReport(zero.reportClass(), []byte(line))
+	if err != nil {
+		t.Fatalf("unmarshalReport(%s) error = %v", line, err)
+	}
+	return r.(T)
+}
+
+// testHandled passes the line to a session as if read from gpsd, and returns the report
+// delivered to the subscribers of T.
+func testHandled[T Report](t *testing.T, line string) T {
+	t.Helper()
+	var got []T
+	s := newSession("localhost:2947")
+	SubscribeFunc(s, func(r T) { got = append(got, r) })
+	s.handleJSON(line)
+	if len(got) != 1 {
+		t.Fatalf("%d reports delivered for %s, want 1", len(got), line)
+	}
+	return got[0]
+}
+
+func TestSKYConstellations(t *testing.T) {
+	sky := testReport[*SKYReport](t, `{"class":"SKY","device":"/dev/ttyACM0","satellites":[`+
+		`{"PRN":5,"gnssid":0,"svid":5,"used":true},`+
+		`{"PRN":133,"gnssid":1,"svid":133,"used":false},`+
+		`{"PRN":301,"gnssid":2,"svid":1,"used":true},`+
+		`{"PRN":401,"gnssid":3,"svid":1,"used":true},`+
+		`{"PRN":173,"gnssid":4,"svid":1,"used":false},`+
+		`{"PRN":193,"gnssid":5,"svid":1,"used":true},`+
+		`{"PRN":65,"gnssid":6,"svid":1,"used":true},`+
+		`{"PRN":1,"gnssid":7,"svid":1,"used":false},`+
+		`{"PRN":9,"gnssid":9,"svid":9,"used":false},`+
+		`{"PRN":12,"used":true},`+
+		`{"PRN":210,"used":false}]}`)
+
+	want := []Constellation{GPS, SBAS, Galileo, BeiDou, IMES, QZSS, GLONASS, NavIC, Constellation(9), GPS, BeiDou}
+	names := []string{"GPS", "SBAS", "Galileo", "BeiDou", "IMES", "QZSS", "GLONASS", "NavIC", "unknown", "GPS", "BeiDou"}
+	if len(sky.Satellites) != len(want) {
+		t.Fatalf("%d satellites, want %d", len(sky.Satellites), len(want))
+	}
+	for i, sat := range sky.Satellites {
+		if got := sat.Constellation(); got != want[i] || got.String() != names[i] {
+			t.Errorf("satellite %d: Constellation() = %v (%d), want %v", sat.PRN, got, got, names[i])
+		}
+	}
+	// gnssid 0 is GPS only when present: PRN 1 with gnssid 7 is NavIC.
+	if sky.Satellites[7].GNSSID != NavIC || !sky.Satellites[0].Has("gnssid") || sky.Satellites[9].Has("gnssid") {
+		t.Errorf("gnssid presence of %+v", sky.Satellites)
+	}
+
+	if n := sky.UsedCount(); n != 6 {
+		t.Errorf("UsedCount() = %d, want 6", n)
+	}
+	groups := sky.ByConstellation()
+	if len(groups[GPS]) != 2 || len(groups[BeiDou]) != 2 || len(groups[Constellation(9)]) != 1 || len(groups) != 9 {
+		t.Errorf("ByConstellation() = %v", groups)
+	}
+}
+
+func TestSKYUsedCount(t *testing.T) {
+	tests := []struct {
+		line string
+		want int
+	}{
+		{`{"class":"SKY","uSat":9,"satellites":[{"PRN":1,"used":true}]}`, 9},
+		{`{"class":"SKY","uSat":0,"satellites":[{"PRN":1,"used":true}]}`, 0},
+		{`{"class":"SKY","satellites":[{"PRN":1,"used":true},{"PRN":2,"used":false},{"PRN":3,"used":true}]}`, 2},
+		{`{"class":"SKY"}`, 0},
+	}
+	for _, tt := range tests {
+		if got := testReport[*SKYReport](t, tt.line).UsedCount(); got != tt.want {
+			t.Errorf("UsedCount() of %s = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestPPSTimes(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		real   time.Time
+		clock  time.Time
+		offset time.Duration
+	}{
+		{
+			name:   "clock behind",
+			line:   `{"class":"PPS","real_sec":1330212592,"real_nsec":0,"clock_sec":1330212591,"clock_nsec":999656816}`,
+			real:   time.Unix(1330212592, 0),
+			clock:  time.Unix(1330212591, 999656816),
+			offset: 343184 * time.Nanosecond,
+		},
+		{
+			name:   "clock ahead",
+			line:   `{"class":"PPS","real_sec":1330212592,"real_nsec":0,"clock_sec":1330212592,"clock_nsec":343184}`,
+			real:   time.Unix(1330212592, 0),
+			clock:  time.Unix(1330212592, 343184),
+			offset: -343184 * time.Nanosecond,
+		},
+		{
+			name:   "microseconds before protocol 3.9",
+			line:   `{"class":"PPS","real_sec":1330212592,"real_musec":0,"clock_sec":1330212592,"clock_musec":343.5}`,
+			real:   time.Unix(1330212592, 0),
+			clock:  time.Unix(1330212592, 343500),
+			offset: -343500 * time.Nanosecond,
+		},
+		{
+			// Nanoseconds take precedence over the deprecated microseconds.
+			name:   "nanoseconds and microseconds",
+			line:   `{"class":"PPS","real_sec":1330212592,"real_nsec":1500,"real_musec":9,"clock_sec":1330212592,"clock_nsec":500,"clock_musec":9}`,
+			real:   time.Unix(1330212592, 1500),
+			clock:  time.Unix(1330212592, 500),
+			offset: time.Microsecond,
+		},
+		{
+			name:   "seconds only",
+			line:   `{"class":"PPS","real_sec":1330212592,"clock_sec":1330212590}`,
+			real:   time.Unix(1330212592, 0),
+			clock:  time.Unix(1330212590, 0),
+			offset: 2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pps := testReport[*PPSReport](t, tt.line)
+			if got := pps.RealTime(); !got.Equal(tt.real) {
+				t.Errorf("RealTime() = %v, want %v", got, tt.real)
+			}
+			if got := pps.ClockTime(); !got.Equal(tt.clock) {
+				t.Errorf("ClockTime() = %v, want %v", got, tt.clock)
+			}
+			if got := pps.Offset(); got != tt.offset {
+				t.Errorf("Offset() = %v, want %v", got, tt.offset)
+			}
+		})
+	}
+}
+
+func TestTOFFTimes(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		real   time.Time
+		clock  time.Time
+		offset time.Duration
+	}{
+		{
+			name:   "clock behind",
+			line:   `{"class":"TOFF","real_sec":1330212592,"real_nsec":343182,"clock_sec":1330212592,"clock_nsec":343180}`,
+			real:   time.Unix(1330212592, 343182),
+			clock:  time.Unix(1330212592, 343180),
+			offset: 2 * time.Nanosecond,
+		},
+		{
+			name:   "clock ahead",
+			line:   `{"class":"TOFF","real_sec":1330212592,"real_nsec":343182,"clock_sec":1330212592,"clock_nsec":120343184}`,
+			real:   time.Unix(1330212592, 343182),
+			clock:  time.Unix(1330212592, 120343184),
+			offset: -120000002 * time.Nanosecond,
+		},
+		{
+			name:   "clock ahead across a second",
+			line:   `{"class":"TOFF","real_sec":1330212591,"real_nsec":999999000,"clock_sec":1330212592,"clock_nsec":1000}`,
+			real:   time.Unix(1330212591, 999999000),
+			clock:  time.Unix(1330212592, 1000),
+			offset: -2 * time.Microsecond,
+		},
+		{
+			name:   "seconds only",
+			line:   `{"class":"TOFF","real_sec":1330212592,"clock_sec":1330212593}`,
+			real:   time.Unix(1330212592, 0),
+			clock:  time.Unix(1330212593, 0),
+			offset: -time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toff := testReport[*TOFFReport](t, tt.line)
+			if got := toff.RealTime(); !got.Equal(tt.real) {
+				t.Errorf("RealTime() = %v, want %v", got, tt.real)
+			}
+			if got := toff.ClockTime(); !got.Equal(tt.clock) {
+				t.Errorf("ClockTime() = %v, want %v", got, tt.clock)
+			}
+			if got := toff.Offset(); got != tt.offset {
+				t.Errorf("Offset() = %v, want %v", got, tt.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package gpsd
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// DefaultRequestTimeout bounds the *Sync methods, which take no context.
+const DefaultRequestTimeout = 5 * time.Second
+
+// pendingRequest is a command waiting for its response.
+type pendingRequest struct {
+	// classes the response may have; an ERROR report always completes a request.
+	classes []string
+	resp    chan response
+}
+
+type response struct {
+	class string
+	line  string
+}
+
+// matches reports whether a report of the class completes the request.
+func (r *pendingRequest) matches(class string) bool {
+	if class == msgClassError {
+		return true
+	}
+	for _, c := range r.classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Request sends the command to gpsd and returns its decoded response of type T:
+
+	version, err := gpsd.Request[*gpsd.VERSIONReport](ctx, session, gpsd.VersionCommand)
+
+Requests may be issued while the stream is running: the response is routed back
+to the caller while other reports keep flowing to subscribers. If no stream is
+running, the response is read from the connection directly and all reports
+received in the meantime are still delivered to subscribers.
+
+An ERROR report sent by gpsd in response is returned as *ProtocolError.
+
+Requests must not be issued from callbacks such as SubscribeFunc or OnError handlers:
+they run on the goroutine that reads the responses, so the request would only fail
+once ctx is done. Receive from a Subscribe channel to issue requests in reaction
+to reports.
+*/
+func Request[T Report](ctx context.Context, s *Session, command string) (T, error) {
+	var zero T
+	class, line, err := s.request(ctx, command, zero.reportClass())
+	if err != nil {
+		return zero, err
+	}
+
+	report, err := unmarshalReport(class, []byte(line))
+	if err != nil {
+		return zero, &DecodeError{Class: class, Line: line, Err: err}
+	}
+	if e, ok := report.(*ERRORReport); ok {
+		return zero, &ProtocolError{Message: e.Message}
+	}
+
+	return report.(T), nil
+}
+
+// VersionReport sends the version command and returns the decoded VERSION response.
+func (s *Session) VersionReport(ctx context.Context) (*VERSIONReport, error) {
+	return Request[*VERSIONReport](ctx, s, VersionCommand)
+}
+
+// PollReport sends the poll command and returns the decoded POLL response with the
+// last-seen fixes of all active devices. It allows to use the session in a request-driven
+// mode, without running the stream: enable watching once with
+//
+//	session.Watch(map[string]bool{"enable": true})
+//
+// and then query the position on demand.
+func (s *Session) PollReport(ctx context.Context) (*POLLReport, error) {
+	return Request[*POLLReport](ctx, s, PollCommand)
+}
+
+// request sends the command and waits for a response of one of the given classes.
+// Requests are serialised since gpsd responses carry no correlation identifier.
+func (s *Session) request(ctx context.Context, command string, classes ...string) (class, line string, err error) {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+
+	req := &pendingRequest{classes: classes, resp: make(chan response, 1)}
+	s.pendingMu.Lock()
+	s.pending = req
+	s.pendingMu.Unlock()
+	defer func() {
+		s.pendingMu.Lock()
+		s.pending = nil
+		s.pendingMu.Unlock()
+	}()
+
+	if err := s.SendCommand(command); err != nil {
+		return "", "", err
+	}
+
+	for {
+		select {
+		case r := <-req.resp:
+			return r.class, r.line, nil
+		default:
+		}
+
+		// A running stream routes the response to us. The connection is shared
+		// with the stream, so its read deadline is left alone.
+		if s.streaming.Load() > 0 {
+			select {
+			case r := <-req.resp:
+				return r.class, r.line, nil
+			case <-ctx.Done():
+				return "", "", ctx.Err()
+			}
+		}
+
+		// Nobody is reading the connection, so do it here.
+		line, err := s.readOwn(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", "", ctx.Err()
+			}
+			return "", "", &ConnError{Op: "read", Address: s.address, Err: err}
+		}
+		s.handleLine(line)
+	}
+}
+
+// readOwn reads a line of a connection no stream is reading, interrupted once ctx is done.
+func (s *Session) readOwn(ctx context.Context) (string, error) {
+	conn, _ := s.conn()
+	defer interruptOnDone(ctx, conn)()
+	return s.readLine()
+}
+
+// resolve completes the pending request, if any, with a report of the given class.
+func (s *Session) resolve(class, line string) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if s.pending != nil && s.pending.matches(class) {
+		s.pending.resp <- response{class: class, line: line}
+		s.pending = nil
+	}
+}
+
+// syncRequest is a request bounded by DefaultRequestTimeout returning the raw response line.
+func (s *Session) syncRequest(command string, classes ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+	defer cancel()
+
+	_, line, _ := s.request(ctx, command, classes...)
+	return line
+}
+
+// responseClass returns the class of the response to the command,
+// e.g. "WATCH" for `WATCH={"enable":true}`.
+func responseClass(command string) string {
+	if i := strings.IndexByte(command, '='); i >= 0 {
+		command = command[:i]
+	}
+	return strings.ToUpper(strings.Trim(command, "?; "))
+}
@@ -0,0 +1,108 @@
+package gpsd
+
+import "encoding/json"
+
+/*
+RTCM2Report is a decoded RTCM version 2 message carrying DGPS corrections
+
+The common fields are always present, the others depend on the message type.
+
+example:
+
+	{"class":"RTCM2","type":1,"station_id":688,"zcount":843.0,"seqnum":5,
+		"length":19,"station_health":6,
+		"satellites":[
+			{"ident":10,"udre":0,"iod":46,"prc":-2.400,"rrc":0.000},
+			{"ident":13,"udre":0,"iod":94,"prc":-4.420,"rrc":0.000}]}
+*/
+type RTCM2Report struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "RTCM2"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Message type, 1 to 63.
+	Type int `json:"type"`
+	// The id of the transmitting station.
+	StationID int `json:"station_id"`
+	// Modified Z-count, in seconds since the start of the hour.
+	ZCount float64 `json:"zcount"`
+	// Sequence number. Only 3 bits wide, wraps after 7.
+	SeqNum int `json:"seqnum"`
+	// The number of words after the header that comprise the message.
+	Length int `json:"length"`
+	// Station transmission status. Indicates the health of the beacon as a reference source.
+	StationHealth int `json:"station_health"`
+
+	// Differential GPS corrections (types 1, 9).
+	Satellites []RTCM2Correction `json:"satellites"`
+	// Reference station ECEF coordinates in meters (type 3).
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	// Special message text (type 16).
+	Message string `json:"message"`
+	// Undecoded words of other message types.
+	Data []json.RawMessage `json:"data"`
+}
+
+// RTCM2Correction is a differential correction for a single satellite.
+type RTCM2Correction struct {
+	// Satellite ID.
+	Ident int `json:"ident"`
+	// User Differential Range Error.
+	UDRE int `json:"udre"`
+	// Issue Of Data, matching the IOD for the current ephemeris of this satellite.
+	IOD int `json:"iod"`
+	// Pseudorange error in meters.
+	PRC float64 `json:"prc"`
+	// Rate of change of pseudorange error in meters per second.
+	RRC float64 `json:"rrc"`
+}
+
+/*
+RTCM3Report is an RTCM version 3 message carrying GNSS corrections
+
+gpsd decodes only a few RTCM3 message types, the others are passed as a hex dump in "data".
+
+example:
+
+	{"class":"RTCM3","device":"/dev/ttyUSB0","type":1005,"length":19,
+		"station_id":2003,"system":["GPS"],"refstation":false,"sro":false,
+		"x":3857167.6475,"y":-5968.0463,"z":5144999.8325}
+*/
+type RTCM3Report struct {
+	// FieldSet tells which fields were present in the report.
+	FieldSet
+	// Fixed: "RTCM3"
+	Class string `json:"class"`
+	// Name of the originating device.
+	Device string `json:"device"`
+	// Message type, e.g. 1005 or 1077.
+	Type int `json:"type"`
+	// Payload length in bytes.
+	Length int `json:"length"`
+	// The id of the transmitting station.
+	StationID int `json:"station_id"`
+	// Hex dump of the payload of messages gpsd does not decode.
+	Data string `json:"data"`
+
+	// Stationary antenna reference point (types 1005, 1006).
+	System     []string `json:"system"`
+	RefStation bool     `json:"refstation"`
+	SRO        bool     `json:"sro"`
+	X          float64  `json:"x"`
+	Y          float64  `json:"y"`
+	Z          float64  `json:"z"`
+	// Antenna height in meters (type 1006).
+	H float64 `json:"h"`
+
+	// Antenna and receiver descriptors (types 1007, 1008, 1033).
+	Desc   string `json:"desc"`
+	Setup  int    `json:"setup"`
+	Serial string `json:"serial"`
+
+	// GPS/GLONASS observables (types 1001-1004, 1009-1012), left undecoded.
+	Satellites json.RawMessage `json:"satellites"`
+}
@@ -0,0 +1,49 @@
+package gpsd
+
+import "testing"
+
+func TestRTCM2Report(t *testing.T) {
+	rtcm := testHandled[*RTCM2Report](t, `{"class":"RTCM2","type":1,"station_id":688,"zcount":843.0,`+
+		`"seqnum":5,"length":19,"station_health":6,"satellites":[`+
+		`{"ident":10,"udre":0,"iod":46,"prc":-2.400,"rrc":0.000},`+
+		`{"ident":13,"udre":0,"iod":94,"prc":-4.420,"rrc":0.000}]}`)
+
+	if rtcm.Type != 1 || rtcm.StationID != 688 || rtcm.ZCount != 843 || rtcm.SeqNum != 5 || rtcm.StationHealth != 6 {
+		t.Errorf("RTCM2Report = %+v", rtcm)
+	}
+	want := []RTCM2Correction{{Ident: 10, IOD: 46, PRC: -2.4}, {Ident: 13, IOD: 94, PRC: -4.42}}
+	if len(rtcm.Satellites) != len(want) {
+		t.Fatalf("Satellites = %+v, want %+v", rtcm.Satellites, want)
+	}
+	for i := range want {
+		if rtcm.Satellites[i] != want[i] {
+			t.Errorf("Satellites[%d] = %+v, want %+v", i, rtcm.Satellites[i], want[i])
+		}
+	}
+	if !rtcm.Has("satellites") || rtcm.Has("x") || rtcm.Has("message") || rtcm.Has("device") {
+		t.Errorf("presence of %+v: satellites %v, x %v, message %v, device %v", rtcm,
+			rtcm.Has("satellites"), rtcm.Has("x"), rtcm.Has("message"), rtcm.Has("device"))
+	}
+}
+
+func TestRTCM3Report(t *testing.T) {
+	rtcm := testHandled[*RTCM3Report](t, `{"class":"RTCM3","device":"/dev/ttyUSB0","type":1005,"length":19,`+
+		`"station_id":2003,"system":["GPS"],"refstation":false,"sro":false,`+
+		`"x":3857167.6475,"y":-5968.0463,"z":5144999.8325}`)
+
+	if rtcm.Type != 1005 || rtcm.StationID != 2003 || rtcm.Device != "/dev/ttyUSB0" ||
+		len(rtcm.System) != 1 || rtcm.System[0] != "GPS" || rtcm.X != 3857167.6475 || rtcm.Y != -5968.0463 {
+		t.Errorf("RTCM3Report = %+v", rtcm)
+	}
+	// The antenna height is only in type 1006 messages, unlike the false flags.
+	if !rtcm.Has("refstation") || !rtcm.Has("sro") || rtcm.Has("h") || rtcm.Has("data") {
+		t.Errorf("presence of %+v: refstation %v, sro %v, h %v, data %v", rtcm,
+			rtcm.Has("refstation"), rtcm.Has("sro"), rtcm.Has("h"), rtcm.Has("data"))
+	}
+
+	// Messages not decoded by gpsd come as a hex dump.
+	rtcm = testHandled[*RTCM3Report](t, `{"class":"RTCM3","type":1230,"length":8,"data":"3e0e5a8000000000"}`)
+	if rtcm.Type != 1230 || rtcm.Data != "3e0e5a8000000000" || !rtcm.Has("data") || rtcm.Has("x") {
+		t.Errorf("RTCM3Report = %+v", rtcm)
+	}
+}
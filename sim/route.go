@@ -0,0 +1,196 @@
+package sim
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// Waypoint is a point of a route.
+type Waypoint struct {
+	// Latitude and longitude in degrees.
+	Lat float64
+	Lon float64
+	// Altitude above mean sea level in meters.
+	Alt float64
+	// Speed in meters per second on the leg starting at the waypoint.
+	// Zero uses the default speed of the simulation.
+	Speed float64
+}
+
+// Route is a list of waypoints travelled in order.
+type Route []Waypoint
+
+// gpx is the subset of the GPX 1.1 schema describing tracks, routes and waypoints.
+type gpx struct {
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  float64   `xml:"lat,attr"`
+	Lon  float64   `xml:"lon,attr"`
+	Ele  float64   `xml:"ele"`
+	Time time.Time `xml:"time"`
+}
+
+/*
+ParseGPX reads a route from a GPX file
+
+The points of all track segments are used, or the route points if there is no track,
+or the waypoints if there is neither. When consecutive points have timestamps,
+the speed of the leg between them is derived from them, so a recorded track
+is replayed at its original pace.
+*/
+func ParseGPX(r io.Reader) (Route, error) {
+	var doc gpx
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("sim: parse GPX: %w", err)
+	}
+
+	var points []gpxPoint
+	for _, trk := range doc.Tracks {
+		for _, seg := range trk.Segments {
+			points = append(points, seg.Points...)
+		}
+	}
+	if len(points) == 0 {
+		for _, rte := range doc.Routes {
+			points = append(points, rte.Points...)
+		}
+	}
+	if len(points) == 0 {
+		points = doc.Waypoints
+	}
+	if len(points) == 0 {
+		return nil, errors.New("sim: GPX has no points")
+	}
+
+	route := make(Route, len(points))
+	for i, p := range points {
+		route[i] = Waypoint{Lat: p.Lat, Lon: p.Lon, Alt: p.Ele}
+		if i+1 < len(points) && !p.Time.IsZero() && !points[i+1].Time.IsZero() {
+			if dt := points[i+1].Time.Sub(p.Time).Seconds(); dt > 0 {
+				route[i].Speed = distance(p.Lat, p.Lon, points[i+1].Lat, points[i+1].Lon) / dt
+			}
+		}
+	}
+	return route, nil
+}
+
+// leg is a segment of a route between two waypoints.
+type leg struct {
+	from, to Waypoint
+	// Length in meters, initial bearing in degrees and duration of the leg.
+	length   float64
+	bearing  float64
+	duration time.Duration
+}
+
+// position is the state of the receiver on the route.
+type position struct {
+	lat, lon, alt float64
+	// Track in degrees, speed and climb in meters per second.
+	track, speed, climb float64
+}
+
+// path is a route prepared for lookups by elapsed time.
+type path struct {
+	legs     []leg
+	duration time.Duration
+	loop     bool
+	last     Waypoint
+}
+
+func newPath(route Route, speed float64, loop bool) path {
+	p := path{loop: loop, last: route[len(route)-1]}
+	for i := 0; i+1 < len(route); i++ {
+		from, to := route[i], route[i+1]
+		v := from.Speed
+		if v <= 0 {
+			v = speed
+		}
+		length := distance(from.Lat, from.Lon, to.Lat, to.Lon)
+		l := leg{
+			from:     from,
+			to:       to,
+			length:   length,
+			bearing:  bearing(from.Lat, from.Lon, to.Lat, to.Lon),
+			duration: time.Duration(length / v * float64(time.Second)),
+		}
+		p.legs = append(p.legs, l)
+		p.duration += l.duration
+	}
+	return p
+}
+
+// at returns the position after travelling the route for elapsed time.
+func (p path) at(elapsed time.Duration) position {
+	if p.loop && p.duration > 0 {
+		elapsed %= p.duration
+	}
+
+	for _, l := range p.legs {
+		if elapsed >= l.duration {
+			elapsed -= l.duration
+			continue
+		}
+
+		seconds := l.duration.Seconds()
+		frac := elapsed.Seconds() / seconds
+		lat, lon := destination(l.from.Lat, l.from.Lon, l.bearing, l.length*frac)
+		return position{
+			lat:   lat,
+			lon:   lon,
+			alt:   l.from.Alt + (l.to.Alt-l.from.Alt)*frac,
+			track: l.bearing,
+			speed: l.length / seconds,
+			climb: (l.to.Alt - l.from.Alt) / seconds,
+		}
+	}
+
+	// The end of the route has been reached.
+	return position{lat: p.last.Lat, lon: p.last.Lon, alt: p.last.Alt}
+}
+
+// distance returns the great-circle distance between two points in meters.
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dPhi, dLambda := radians(lat2-lat1), radians(lon2-lon1)
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// bearing returns the initial bearing from the first point to the second one in degrees from true north.
+func bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dLambda := radians(lon2 - lon1)
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// destination returns the point at the distance in meters from the start along the bearing.
+func destination(lat, lon, brng, dist float64) (float64, float64) {
+	phi1, lambda1, theta := radians(lat), radians(lon), radians(brng)
+	delta := dist / earthRadius
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi1), math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+	return degrees(phi2), math.Mod(degrees(lambda2)+540, 360) - 180
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
@@ -0,0 +1,96 @@
+package sim
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vpakhuchyi/go-gpsd/gpsdserver"
+)
+
+/*
+Server streams the reports of a Simulator to its clients in real time
+
+It is a gpsdserver.Server reporting Config.Device: it answers the usual gpsd commands,
+?POLL with the reports of the last epoch, and can be scripted further. The reports are sent to every
+connected client, one epoch per Config.Rate.
+*/
+type Server struct {
+	*gpsdserver.Server
+
+	sim  *Simulator
+	stop chan struct{}
+	done chan struct{}
+
+	mu   sync.Mutex
+	last Epoch
+}
+
+// NewServer starts a server of the simulation listening on the TCP address,
+// e.g. "localhost:2947". An empty address listens on a random local port.
+func NewServer(address string, cfg Config) (*Server, error) {
+	sim, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if address == "" {
+		address = "127.0.0.1:0"
+	}
+	srv, err := gpsdserver.Listen(address, gpsdserver.WithDevice(sim.cfg.Device))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Server: srv,
+		sim:    sim,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.HandleFunc("POLL", s.poll)
+	go s.run()
+	return s, nil
+}
+
+// Close stops the simulation and the server.
+func (s *Server) Close() {
+	close(s.stop)
+	<-s.done
+	s.Server.Close()
+}
+
+func (s *Server) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.sim.cfg.Rate)
+	defer ticker.Stop()
+	for {
+		epoch := s.sim.Next()
+		s.mu.Lock()
+		s.last = epoch
+		s.mu.Unlock()
+		s.Send(epoch.Lines...)
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll returns the POLL report of the last epoch.
+func (s *Server) poll(string) []string {
+	s.mu.Lock()
+	epoch := s.last
+	s.mu.Unlock()
+
+	if len(epoch.Lines) == 0 {
+		return []string{`{"class":"POLL","active":0,"tpv":[],"gst":[],"sky":[]}`}
+	}
+	gst := ""
+	if len(epoch.Lines) > 2 {
+		gst = epoch.Lines[2]
+	}
+	return []string{`{"class":"POLL","time":"` + epoch.Time.Format("2006-01-02T15:04:05.000Z") +
+		`","active":1,"tpv":[` + epoch.Lines[0] + `],"gst":[` + gst + `],"sky":[` + epoch.Lines[1] + `]}`}
+}
@@ -0,0 +1,287 @@
+/*
+Package sim generates synthetic gpsd reports from a trajectory.
+
+A Simulator moves a receiver along a Route, given as waypoints or read from a GPX file,
+and produces TPV, SKY and GST reports for every epoch, with position noise scaled by
+the geometry of simulated satellites and random fix dropouts. A Server serves
+the reports on a gpsd-compatible TCP endpoint that gpsd.Dial can connect to:
+
+	route, err := sim.ParseGPX(file)
+	srv, err := sim.NewServer("localhost:2947", sim.Config{Route: route, Noise: 2, DropoutRate: 0.01})
+	defer srv.Close()
+
+	session, err := gpsd.Dial(srv.Addr)
+*/
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/vpakhuchyi/go-gpsd"
+	"github.com/vpakhuchyi/go-gpsd/gpsdserver"
+)
+
+// Defaults of Config.
+const (
+	DefaultSpeed           = 10.0
+	DefaultRate            = time.Second
+	DefaultDropoutDuration = 5 * time.Second
+	DefaultSatellites      = 24
+	DefaultElevationMask   = 10.0
+)
+
+// geoidSeparation is the height of the geoid above the WGS84 ellipsoid in meters,
+// which varies by about ±100 m over the globe and is kept constant for simplicity.
+const geoidSeparation = 45.0
+
+// DefaultConstellations are the constellations of the simulated satellites by default.
+var DefaultConstellations = []gpsd.Constellation{gpsd.GPS, gpsd.Galileo, gpsd.GLONASS, gpsd.BeiDou}
+
+// Config configures a Simulator. Zero values are replaced by the defaults.
+type Config struct {
+	// Route travelled by the receiver. A single waypoint simulates a static receiver.
+	Route Route
+	// Speed in meters per second on legs of waypoints without one.
+	Speed float64
+	// Loop restarts the route once its end is reached, otherwise the receiver stays at the last waypoint.
+	Loop bool
+	// Rate is the interval between epochs.
+	Rate time.Duration
+	// Start is the time of the first epoch, the current time by default.
+	Start time.Time
+	// Device reported in the reports, gpsdserver.DevicePath by default.
+	Device string
+
+	// Noise is the user equivalent range error in meters (1-sigma). The position noise is
+	// this error scaled by the dilution of precision of the satellite geometry. Zero disables noise.
+	Noise float64
+	// DropoutRate is the probability that the fix is lost at any epoch.
+	DropoutRate float64
+	// DropoutDuration is how long the fix stays lost.
+	DropoutDuration time.Duration
+
+	// Satellites is the number of simulated satellites, about two thirds of them above the horizon.
+	Satellites int
+	// Constellations of the satellites, DefaultConstellations by default.
+	Constellations []gpsd.Constellation
+	// ElevationMask in degrees: satellites below it are not used in the solution.
+	ElevationMask float64
+	// Seed of the random generator: a simulation is reproducible for a given seed.
+	Seed int64
+}
+
+// Epoch holds the reports of an epoch.
+type Epoch struct {
+	Time time.Time
+	TPV  *gpsd.TPVReport
+	SKY  *gpsd.SKYReport
+	// GST is nil without a fix.
+	GST *gpsd.GSTReport
+	// Lines are the reports encoded as gpsd sends them, in the order TPV, SKY and GST.
+	Lines []string
+}
+
+// Simulator generates the reports of a receiver moving along a route.
+type Simulator struct {
+	cfg          Config
+	path         path
+	sats         []satellite
+	rnd          *rand.Rand
+	epoch        int
+	dropoutUntil time.Duration
+}
+
+// New returns a simulator of the configuration.
+func New(cfg Config) (*Simulator, error) {
+	if len(cfg.Route) == 0 {
+		return nil, errors.New("sim: route is empty")
+	}
+	if cfg.Speed <= 0 {
+		cfg.Speed = DefaultSpeed
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = DefaultRate
+	}
+	if cfg.Start.IsZero() {
+		cfg.Start = time.Now()
+	}
+	if cfg.Device == "" {
+		cfg.Device = gpsdserver.DevicePath
+	}
+	if cfg.DropoutDuration <= 0 {
+		cfg.DropoutDuration = DefaultDropoutDuration
+	}
+	if cfg.Satellites <= 0 {
+		cfg.Satellites = DefaultSatellites
+	}
+	if len(cfg.Constellations) == 0 {
+		cfg.Constellations = DefaultConstellations
+	}
+	if cfg.ElevationMask <= 0 {
+		cfg.ElevationMask = DefaultElevationMask
+	}
+
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+	return &Simulator{
+		cfg:  cfg,
+		path: newPath(cfg.Route, cfg.Speed, cfg.Loop),
+		sats: newSatellites(cfg.Satellites, cfg.Constellations, rnd),
+		rnd:  rnd,
+	}, nil
+}
+
+// Next returns the reports of the next epoch. It doesn't wait: epochs are
+// Config.Rate apart in simulated time, whatever the pace of the calls.
+func (s *Simulator) Next() Epoch {
+	elapsed := time.Duration(s.epoch) * s.cfg.Rate
+	now := s.cfg.Start.Add(elapsed).UTC()
+	s.epoch++
+
+	if elapsed >= s.dropoutUntil && s.rnd.Float64() < s.cfg.DropoutRate {
+		s.dropoutUntil = elapsed + s.cfg.DropoutDuration
+	}
+	dropout := elapsed < s.dropoutUntil
+
+	sky := make([]skyView, 0, len(s.sats))
+	for _, sat := range s.sats {
+		el, az := sat.at(elapsed)
+		if el <= 0 {
+			continue
+		}
+		ss := math.Max(0, 25+25*math.Sin(radians(el))+1.5*s.rnd.NormFloat64())
+		sky = append(sky, skyView{
+			satellite: sat,
+			elevation: el,
+			azimuth:   az,
+			ss:        ss,
+			used:      !dropout && el >= s.cfg.ElevationMask,
+		})
+	}
+	d, fix := computeDOPs(sky)
+
+	pos := s.path.at(elapsed)
+	tpv := map[string]interface{}{
+		"class":  "TPV",
+		"device": s.cfg.Device,
+		"mode":   gpsd.NoFix,
+		"time":   now,
+	}
+	var gst map[string]interface{}
+	if fix {
+		// Position errors (1-sigma) in meters east, north and up.
+		sigmaE, sigmaN, sigmaU := s.cfg.Noise*d.x, s.cfg.Noise*d.y, s.cfg.Noise*d.v
+		lat, lon := destination(pos.lat, pos.lon, 0, sigmaN*s.rnd.NormFloat64())
+		lat, lon = destination(lat, lon, 90, sigmaE*s.rnd.NormFloat64())
+		alt := pos.alt + sigmaU*s.rnd.NormFloat64()
+		track := pos.track
+		velN := pos.speed * math.Cos(radians(track))
+		velE := pos.speed * math.Sin(radians(track))
+
+		tpv["mode"] = gpsd.Mode3D
+		tpv["ept"] = 0.005
+		tpv["lat"] = round(lat, 9)
+		tpv["lon"] = round(lon, 9)
+		tpv["altHAE"] = round(alt+geoidSeparation, 3)
+		tpv["altMSL"] = round(alt, 3)
+		tpv["geoidSep"] = geoidSeparation
+		tpv["epx"] = round(2*sigmaE, 3)
+		tpv["epy"] = round(2*sigmaN, 3)
+		tpv["epv"] = round(2*sigmaU, 3)
+		tpv["eph"] = round(2*math.Hypot(sigmaE, sigmaN), 3)
+		tpv["sep"] = round(2*math.Sqrt(sigmaE*sigmaE+sigmaN*sigmaN+sigmaU*sigmaU), 3)
+		tpv["speed"] = round(pos.speed, 3)
+		tpv["climb"] = round(pos.climb, 3)
+		tpv["velN"] = round(velN, 3)
+		tpv["velE"] = round(velE, 3)
+		tpv["velD"] = round(-pos.climb, 3)
+		if pos.speed > 0 {
+			tpv["track"] = round(track, 4)
+		}
+
+		major, minor, orient := sigmaN, sigmaE, 0.0
+		if sigmaE > sigmaN {
+			major, minor, orient = sigmaE, sigmaN, 90.0
+		}
+		gst = map[string]interface{}{
+			"class":  "GST",
+			"device": s.cfg.Device,
+			"time":   now,
+			"rms":    round(s.cfg.Noise, 3),
+			"major":  round(major, 3),
+			"minor":  round(minor, 3),
+			"orient": orient,
+			"lat":    round(sigmaN, 3),
+			"lon":    round(sigmaE, 3),
+			"alt":    round(sigmaU, 3),
+		}
+	}
+
+	satellites := make([]map[string]interface{}, len(sky))
+	used := 0
+	for i, v := range sky {
+		qual := 1
+		if v.used {
+			qual = 7
+			used++
+		}
+		satellites[i] = map[string]interface{}{
+			"PRN":    v.prn(),
+			"gnssid": v.gnssID,
+			"svid":   v.svID,
+			"el":     round(v.elevation, 1),
+			"az":     round(v.azimuth, 1),
+			"ss":     round(v.ss, 1),
+			"used":   v.used,
+			"health": 1,
+			"qual":   qual,
+		}
+	}
+	skyReport := map[string]interface{}{
+		"class":      "SKY",
+		"device":     s.cfg.Device,
+		"time":       now,
+		"nSat":       len(sky),
+		"uSat":       used,
+		"satellites": satellites,
+	}
+	if fix {
+		skyReport["xdop"] = round(d.x, 2)
+		skyReport["ydop"] = round(d.y, 2)
+		skyReport["vdop"] = round(d.v, 2)
+		skyReport["hdop"] = round(d.h, 2)
+		skyReport["pdop"] = round(d.p, 2)
+		skyReport["tdop"] = round(d.t, 2)
+		skyReport["gdop"] = round(d.g, 2)
+	}
+
+	epoch := Epoch{Time: now, TPV: &gpsd.TPVReport{}, SKY: &gpsd.SKYReport{}}
+	epoch.Lines = append(epoch.Lines, encode(tpv, epoch.TPV), encode(skyReport, epoch.SKY))
+	if gst != nil {
+		epoch.GST = &gpsd.GSTReport{}
+		epoch.Lines = append(epoch.Lines, encode(gst, epoch.GST))
+	}
+	return epoch
+}
+
+// encode returns the JSON line of the report fields and decodes it into r,
+// so that r records the present fields exactly as a client would.
+func encode(fields map[string]interface{}, r interface{}) string {
+	if t, ok := fields["time"].(time.Time); ok {
+		fields["time"] = t.Format("2006-01-02T15:04:05.000Z")
+	}
+	// The fields are plain values that can always be marshalled and decoded.
+	line, _ := json.Marshal(fields)
+	_ = json.Unmarshal(line, r)
+	return string(line)
+}
+
+// round rounds v to the number of decimal places, like gpsd's fixed precision output.
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	// Adding zero turns a negative zero into a positive one.
+	return math.Round(v*p)/p + 0
+}
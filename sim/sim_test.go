@@ -0,0 +1,216 @@
+package sim
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vpakhuchyi/go-gpsd"
+	"github.com/vpakhuchyi/go-gpsd/gpsdserver"
+)
+
+// start is the time of the first epoch of the tests.
+var start = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func newSimulator(t *testing.T, cfg Config) *Simulator {
+	t.Helper()
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestPathInterpolation(t *testing.T) {
+	// About 1112 m north at the default speed, then back south twice as fast.
+	route := Route{{Lat: 50, Lon: 30}, {Lat: 50.01, Lon: 30, Alt: 100, Speed: 20}, {Lat: 50, Lon: 30}}
+	length := distance(50, 30, 50.01, 30)
+	first := time.Duration(length / DefaultSpeed * float64(time.Second))
+
+	tests := []struct {
+		name    string
+		loop    bool
+		elapsed time.Duration
+		want    position
+	}{
+		{name: "start", elapsed: 0,
+			want: position{lat: 50, lon: 30, track: 0, speed: DefaultSpeed, climb: 100 / first.Seconds()}},
+		{name: "middle of the first leg", elapsed: first / 2,
+			want: position{lat: 50.005, lon: 30, alt: 50, track: 0, speed: DefaultSpeed, climb: 100 / first.Seconds()}},
+		{name: "middle of the second leg", elapsed: first + first/4,
+			want: position{lat: 50.005, lon: 30, alt: 50, track: 180, speed: 20, climb: -200 / first.Seconds()}},
+		{name: "end", elapsed: first + first/2 + time.Second,
+			want: position{lat: 50, lon: 30}},
+		{name: "looped", loop: true, elapsed: first + first/2 + first/2,
+			want: position{lat: 50.005, lon: 30, alt: 50, track: 0, speed: DefaultSpeed, climb: 100 / first.Seconds()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newPath(route, DefaultSpeed, tt.loop).at(tt.elapsed)
+			w := tt.want
+			if !near(got.lat, w.lat, 1e-6) || !near(got.lon, w.lon, 1e-6) || !near(got.alt, w.alt, 0.01) ||
+				!near(got.track, w.track, 1e-3) || !near(got.speed, w.speed, 0.01) || !near(got.climb, w.climb, 1e-3) {
+				t.Errorf("at(%v) = %+v, want %+v", tt.elapsed, got, w)
+			}
+		})
+	}
+}
+
+func TestSimulatorRate(t *testing.T) {
+	cfg := Config{Route: Route{{Lat: 50.45, Lon: 30.52, Alt: 180}}, Rate: 200 * time.Millisecond, Start: start}
+	s := newSimulator(t, cfg)
+
+	for i := 0; i < 10; i++ {
+		epoch := s.Next()
+		want := start.Add(time.Duration(i) * cfg.Rate)
+		if !epoch.Time.Equal(want) || !epoch.TPV.Time.Equal(want) || !epoch.SKY.Time.Equal(want) {
+			t.Fatalf("epoch %d at %v, TPV %v, SKY %v, want %v", i, epoch.Time, epoch.TPV.Time, epoch.SKY.Time, want)
+		}
+		// Without noise the fix is the waypoint.
+		tpv := epoch.TPV
+		if tpv.Mode != gpsd.Mode3D || tpv.Lat != 50.45 || tpv.Lon != 30.52 || tpv.AltMSL != 180 ||
+			tpv.AltHAE != 180+geoidSeparation || tpv.Device != gpsdserver.DevicePath {
+			t.Errorf("epoch %d: TPV = %+v", i, tpv)
+		}
+		if epoch.GST == nil || len(epoch.Lines) != 3 {
+			t.Errorf("epoch %d: %d lines and GST %v, want TPV, SKY and GST", i, len(epoch.Lines), epoch.GST)
+		}
+	}
+}
+
+func TestSimulatorSeed(t *testing.T) {
+	cfg := Config{Route: Route{{Lat: 50.45, Lon: 30.52}, {Lat: 50.46, Lon: 30.53}}, Start: start,
+		Noise: 2, DropoutRate: 0.05, Seed: 42}
+	a, b := newSimulator(t, cfg), newSimulator(t, cfg)
+	cfg.Seed = 43
+	c := newSimulator(t, cfg)
+
+	differ := false
+	for i := 0; i < 20; i++ {
+		ea, eb, ec := a.Next(), b.Next(), c.Next()
+		for j := range ea.Lines {
+			if j >= len(eb.Lines) || ea.Lines[j] != eb.Lines[j] {
+				t.Fatalf("epoch %d differs for the same seed:\n%v\n%v", i, ea.Lines, eb.Lines)
+			}
+		}
+		differ = differ || ea.Lines[0] != ec.Lines[0]
+	}
+	if !differ {
+		t.Error("another seed gives the same positions")
+	}
+}
+
+func TestSimulatorNoise(t *testing.T) {
+	s := newSimulator(t, Config{Route: Route{{Lat: 50.45, Lon: 30.52}}, Start: start, Noise: 2, Seed: 1})
+
+	// The errors are twice the 1-sigma errors of the GST report, and the position stays within a few of them.
+	for i := 0; i < 100; i++ {
+		epoch := s.Next()
+		tpv, gst := epoch.TPV, epoch.GST
+		if !near(tpv.Epy, 2*gst.Lat, 0.002) || !near(tpv.Epx, 2*gst.Lon, 0.002) || !near(tpv.Epv, 2*gst.Alt, 0.002) {
+			t.Fatalf("epoch %d: epx %v epy %v epv %v, GST %+v", i, tpv.Epx, tpv.Epy, tpv.Epv, gst)
+		}
+		if d := distance(tpv.Lat, tpv.Lon, 50.45, 30.52); d > 3*tpv.Eph {
+			t.Errorf("epoch %d: %.1f m from the waypoint with an eph of %v", i, d, tpv.Eph)
+		}
+	}
+}
+
+func TestSimulatorDropouts(t *testing.T) {
+	route := Route{{Lat: 50.45, Lon: 30.52}}
+	s := newSimulator(t, Config{Route: route, Start: start, DropoutRate: 1})
+	for i := 0; i < 10; i++ {
+		epoch := s.Next()
+		if epoch.TPV.Mode != gpsd.NoFix || epoch.TPV.Has("lat") || epoch.GST != nil || len(epoch.Lines) != 2 ||
+			epoch.SKY.UsedCount() != 0 || len(epoch.SKY.Satellites) == 0 {
+			t.Fatalf("epoch %d: TPV %+v, GST %v, %d used of %d satellites, want no fix",
+				i, epoch.TPV, epoch.GST, epoch.SKY.UsedCount(), len(epoch.SKY.Satellites))
+		}
+	}
+
+	// Every dropout lasts DropoutDuration, 3 epochs.
+	s = newSimulator(t, Config{Route: route, Start: start, DropoutRate: 0.1, DropoutDuration: 3 * time.Second, Seed: 7})
+	var runs []int
+	run := 0
+	for i := 0; i < 500; i++ {
+		if s.Next().TPV.Mode == gpsd.NoFix {
+			run++
+			continue
+		}
+		if run > 0 {
+			runs = append(runs, run)
+		}
+		run = 0
+	}
+	if len(runs) < 5 {
+		t.Fatalf("dropouts = %v, want some", runs)
+	}
+	for _, n := range runs {
+		if n%3 != 0 {
+			t.Errorf("dropouts = %v epochs long, want multiples of 3", runs)
+			break
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	srv, err := NewServer("", Config{
+		Route: Route{{Lat: 50.45, Lon: 30.52, Alt: 180}},
+		Rate:  20 * time.Millisecond,
+		Noise: 1,
+		Seed:  1,
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	defer srv.Close()
+
+	session, err := gpsd.Dial(srv.Addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer session.Close()
+	tpvs := gpsd.Subscribe[*gpsd.TPVReport](session, 4)
+	skys := gpsd.Subscribe[*gpsd.SKYReport](session, 4)
+	gsts := gpsd.Subscribe[*gpsd.GSTReport](session, 4)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- session.RunContext(ctx, "json") }()
+
+	select {
+	case tpv := <-tpvs:
+		if tpv.Device != gpsdserver.DevicePath || tpv.Mode != gpsd.Mode3D || !tpv.Has("altHAE") ||
+			tpv.GeoidSep != geoidSeparation || distance(tpv.Lat, tpv.Lon, 50.45, 30.52) > 20 {
+			t.Errorf("TPV = %+v", tpv)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for TPV")
+	}
+	select {
+	case sky := <-skys:
+		if sky.UsedCount() < 4 || !sky.Has("hdop") || sky.ByConstellation()[gpsd.GPS] == nil {
+			t.Errorf("SKY = %+v", sky)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for SKY")
+	}
+	select {
+	case gst := <-gsts:
+		if gst.Device != gpsdserver.DevicePath || gst.Rms != 1 {
+			t.Errorf("GST = %+v", gst)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for GST")
+	}
+
+	cancel()
+	<-done
+}
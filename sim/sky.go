@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/vpakhuchyi/go-gpsd"
+)
+
+// Apparent motion of simulated satellites: a pass over the sky lasts about 8 hours
+// and the azimuth drifts by 15 degrees per hour.
+const (
+	passPeriod   = 12 * time.Hour
+	azimuthDrift = 15.0 / 3600
+)
+
+// satellite is a simulated satellite on a simplified sky track.
+type satellite struct {
+	gnssID gpsd.Constellation
+	svID   int
+	// Maximum elevation of the pass in degrees, azimuth at the start of the simulation
+	// in degrees and phase of the pass in radians.
+	maxElevation float64
+	azimuth      float64
+	phase        float64
+}
+
+// skyView is a satellite as seen at an epoch.
+type skyView struct {
+	satellite
+	elevation, azimuth, ss float64
+	used                   bool
+}
+
+// dops are the dilutions of precision of a satellite geometry.
+type dops struct {
+	x, y, v, h, p, t, g float64
+}
+
+func newSatellites(n int, constellations []gpsd.Constellation, rnd *rand.Rand) []satellite {
+	sats := make([]satellite, n)
+	for i := range sats {
+		sats[i] = satellite{
+			gnssID:       constellations[i%len(constellations)],
+			svID:         i/len(constellations) + 1,
+			maxElevation: 30 + 60*rnd.Float64(),
+			azimuth:      360 * rnd.Float64(),
+			phase:        2 * math.Pi * rnd.Float64(),
+		}
+	}
+	return sats
+}
+
+// at returns the elevation and azimuth of the satellite in degrees after elapsed time.
+// The satellite is above the horizon two thirds of the time.
+func (s satellite) at(elapsed time.Duration) (elevation, azimuth float64) {
+	angle := s.phase + 2*math.Pi*elapsed.Seconds()/passPeriod.Seconds()
+	elevation = s.maxElevation * (math.Sin(angle) + 0.5) / 1.5
+	azimuth = math.Mod(s.azimuth+azimuthDrift*elapsed.Seconds(), 360)
+	return elevation, azimuth
+}
+
+// prn returns the PRN of the satellite in the numbering used by gpsd.
+func (s satellite) prn() int {
+	switch s.gnssID {
+	case gpsd.GLONASS:
+		return 64 + s.svID
+	case gpsd.SBAS:
+		return 119 + s.svID
+	case gpsd.IMES:
+		return 172 + s.svID
+	case gpsd.QZSS:
+		return 192 + s.svID
+	case gpsd.Galileo:
+		return 300 + s.svID
+	case gpsd.BeiDou:
+		return 400 + s.svID
+	default:
+		return s.svID
+	}
+}
+
+// computeDOPs returns the dilutions of precision of the satellites used, or false if
+// there are less than 4 of them or their geometry is degenerate.
+func computeDOPs(sky []skyView) (dops, bool) {
+	// Normal matrix of the least squares solution for east, north, up and clock bias.
+	var n [4][4]float64
+	used := 0
+	for _, s := range sky {
+		if !s.used {
+			continue
+		}
+		used++
+		el, az := radians(s.elevation), radians(s.azimuth)
+		row := [4]float64{-math.Cos(el) * math.Sin(az), -math.Cos(el) * math.Cos(az), -math.Sin(el), 1}
+		for i := range row {
+			for j := range row {
+				n[i][j] += row[i] * row[j]
+			}
+		}
+	}
+	if used < 4 {
+		return dops{}, false
+	}
+
+	q, ok := invert(n)
+	if !ok {
+		return dops{}, false
+	}
+	return dops{
+		x: math.Sqrt(q[0][0]),
+		y: math.Sqrt(q[1][1]),
+		v: math.Sqrt(q[2][2]),
+		h: math.Sqrt(q[0][0] + q[1][1]),
+		p: math.Sqrt(q[0][0] + q[1][1] + q[2][2]),
+		t: math.Sqrt(q[3][3]),
+		g: math.Sqrt(q[0][0] + q[1][1] + q[2][2] + q[3][3]),
+	}, true
+}
+
+// invert inverts the matrix with Gauss-Jordan elimination.
+func invert(m [4][4]float64) ([4][4]float64, bool) {
+	var inv [4][4]float64
+	for i := range inv {
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for row := col + 1; row < 4; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-12 {
+			return inv, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		div := m[col][col]
+		for j := 0; j < 4; j++ {
+			m[col][j] /= div
+			inv[col][j] /= div
+		}
+		for row := 0; row < 4; row++ {
+			if row == col {
+				continue
+			}
+			f := m[row][col]
+			for j := 0; j < 4; j++ {
+				m[row][j] -= f * m[col][j]
+				inv[row][j] -= f * inv[col][j]
+			}
+		}
+	}
+	return inv, true
+}
@@ -0,0 +1,132 @@
+package gpsd
+
+import (
+	"sync"
+
+	"github.com/vpakhuchyi/go-gpsd/nmea"
+)
+
+// Report is implemented by every typed gpsd report delivered to subscribers,
+// e.g. *TPVReport or *SKYReport. It allows Subscribe and SubscribeFunc to
+// derive the report class from the type parameter at compile time.
+type Report interface {
+	reportClass() string
+}
+
+/*
+Subscribe returns a channel receiving every report of type T read by the session:
+
+	tpv := gpsd.Subscribe[*gpsd.TPVReport](session, 16)
+	for r := range tpv {
+		fmt.Println(r.Lat, r.Lon)
+	}
+
+The channel is buffered with the given size (at least 1). Delivery never blocks
+the session: when the buffer is full, the oldest buffered report is dropped to
+make room for the new one, so a slow consumer always sees the most recent data.
+The channel is closed when the session is closed.
+*/
+func Subscribe[T Report](s *Session, size int) <-chan T {
+	if size < 1 {
+		size = 1
+	}
+	sub := &subscription[T]{ch: make(chan T, size)}
+
+	var zero T
+	s.Subscribe(zero.reportClass(), func(r interface{}) {
+		if v, ok := r.(T); ok {
+			sub.send(v)
+		}
+	})
+	s.onClose(sub.close)
+
+	return sub.ch
+}
+
+// SubscribeFunc registers f to be called with every report of type T read by the session.
+// f is called synchronously from the session goroutine, so it must not block
+// nor issue requests on the session, see Request.
+func SubscribeFunc[T Report](s *Session, f func(T)) {
+	var zero T
+	s.Subscribe(zero.reportClass(), func(r interface{}) {
+		if v, ok := r.(T); ok {
+			f(v)
+		}
+	})
+}
+
+/*
+SubscribeNMEA returns a channel receiving every NMEA sentence of type T read by the session
+in nmea and json+nmea watch modes, parsed and with a valid checksum:
+
+	gga := gpsd.SubscribeNMEA[nmea.GGA](session, 16)
+	for s := range gga {
+		fmt.Println(s.Latitude, s.Longitude)
+	}
+
+Use nmea.Sentence as T to receive sentences of all types.
+The buffering and drop policy are the same as for Subscribe.
+*/
+func SubscribeNMEA[T nmea.Sentence](s *Session, size int) <-chan T {
+	if size < 1 {
+		size = 1
+	}
+	sub := &subscription[T]{ch: make(chan T, size)}
+
+	s.subscribeSentences(func(sentence nmea.Sentence) {
+		if v, ok := sentence.(T); ok {
+			sub.send(v)
+		}
+	})
+	s.onClose(sub.close)
+
+	return sub.ch
+}
+
+// SubscribeNMEAFunc registers f to be called with every NMEA sentence of type T read by the session.
+// f is called synchronously from the session goroutine, so it must not block
+// nor issue requests on the session, see Request.
+func SubscribeNMEAFunc[T nmea.Sentence](s *Session, f func(T)) {
+	s.subscribeSentences(func(sentence nmea.Sentence) {
+		if v, ok := sentence.(T); ok {
+			f(v)
+		}
+	})
+}
+
+// subscription is a channel fed by a session that drops the oldest value on overflow.
+type subscription[T any] struct {
+	mu     sync.Mutex
+	ch     chan T
+	closed bool
+}
+
+func (sub *subscription[T]) send(v T) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return
+	}
+
+	for {
+		select {
+		case sub.ch <- v:
+			return
+		default:
+		}
+		// The buffer is full: discard the oldest value and try again.
+		select {
+		case <-sub.ch:
+		default:
+		}
+	}
+}
+
+func (sub *subscription[T]) close() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if !sub.closed {
+		sub.closed = true
+		close(sub.ch)
+	}
+}
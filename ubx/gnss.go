@@ -0,0 +1,40 @@
+package ubx
+
+// GNSSID identifies the constellation of a satellite or signal.
+type GNSSID uint8
+
+// GNSS identifiers, matching gpsd's gnssid.
+const (
+	GNSSGPS     GNSSID = 0
+	GNSSSBAS    GNSSID = 1
+	GNSSGalileo GNSSID = 2
+	GNSSBeiDou  GNSSID = 3
+	GNSSIMES    GNSSID = 4
+	GNSSQZSS    GNSSID = 5
+	GNSSGLONASS GNSSID = 6
+	GNSSNavIC   GNSSID = 7
+)
+
+// String implements fmt.Stringer interface.
+func (g GNSSID) String() string {
+	switch g {
+	case GNSSGPS:
+		return "GPS"
+	case GNSSSBAS:
+		return "SBAS"
+	case GNSSGalileo:
+		return "Galileo"
+	case GNSSBeiDou:
+		return "BeiDou"
+	case GNSSIMES:
+		return "IMES"
+	case GNSSQZSS:
+		return "QZSS"
+	case GNSSGLONASS:
+		return "GLONASS"
+	case GNSSNavIC:
+		return "NavIC"
+	default:
+		return "unknown"
+	}
+}
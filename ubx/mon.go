@@ -0,0 +1,115 @@
+package ubx
+
+// Message IDs of the MON class.
+const (
+	IDMonHW = 0x09
+)
+
+// AntennaStatus is the status of the antenna supervisor.
+type AntennaStatus uint8
+
+// Antenna statuses of MON-HW messages.
+const (
+	AntennaInit     AntennaStatus = 0
+	AntennaDontKnow AntennaStatus = 1
+	AntennaOK       AntennaStatus = 2
+	AntennaShort    AntennaStatus = 3
+	AntennaOpen     AntennaStatus = 4
+)
+
+// String implements fmt.Stringer interface.
+func (a AntennaStatus) String() string {
+	switch a {
+	case AntennaInit:
+		return "init"
+	case AntennaDontKnow:
+		return "unknown"
+	case AntennaOK:
+		return "ok"
+	case AntennaShort:
+		return "short"
+	case AntennaOpen:
+		return "open"
+	default:
+		return "invalid"
+	}
+}
+
+// AntennaPower is the power status of the antenna.
+type AntennaPower uint8
+
+// Antenna power statuses of MON-HW messages.
+const (
+	AntennaPowerOff      AntennaPower = 0
+	AntennaPowerOn       AntennaPower = 1
+	AntennaPowerDontKnow AntennaPower = 2
+)
+
+// JammingState is the output of the CW jamming indicator.
+type JammingState uint8
+
+// Jamming states of MON-HW messages.
+const (
+	JammingUnknown  JammingState = 0
+	JammingOK       JammingState = 1
+	JammingWarning  JammingState = 2
+	JammingCritical JammingState = 3
+)
+
+// MonHW is a hardware status message (UBX-MON-HW).
+type MonHW struct {
+	Packet
+	// Mask of pins set as peripheral or PIO, bank, direction and value.
+	PinSel  uint32
+	PinBank uint32
+	PinDir  uint32
+	PinVal  uint32
+	// Noise level as measured by the GPS core.
+	NoisePerMS uint16
+	// AGC monitor, from 0 to 8191.
+	AGCCnt        uint16
+	AntennaStatus AntennaStatus
+	AntennaPower  AntennaPower
+	// RTCCalib is set if the RTC is calibrated.
+	RTCCalib     bool
+	JammingState JammingState
+	// Mask of pins used by virtual peripherals.
+	UsedMask uint32
+	// Mapping of the 17 virtual pins to physical pins.
+	VP [17]uint8
+	// CW jamming indicator, from 0 (no CW jamming) to 255 (strong CW jamming).
+	JamInd uint8
+	// Mask of pins with an interrupt, a pull-high and a pull-low resistor.
+	PinIrq uint32
+	PullH  uint32
+	PullL  uint32
+}
+
+func newMonHW(p Packet) (MonHW, error) {
+	d, err := newDecoder(p, 60, 0)
+	if err != nil {
+		return MonHW{}, err
+	}
+
+	flags := d.U1(22)
+	hw := MonHW{
+		Packet:        p,
+		PinSel:        d.U4(0),
+		PinBank:       d.U4(4),
+		PinDir:        d.U4(8),
+		PinVal:        d.U4(12),
+		NoisePerMS:    d.U2(16),
+		AGCCnt:        d.U2(18),
+		AntennaStatus: AntennaStatus(d.U1(20)),
+		AntennaPower:  AntennaPower(d.U1(21)),
+		RTCCalib:      bit(flags, 0),
+		JammingState:  JammingState(flags >> 2 & 0x3),
+		UsedMask:      d.U4(24),
+		JamInd:        d.U1(45),
+		PinIrq:        d.U4(48),
+		PullH:         d.U4(52),
+		PullL:         d.U4(56),
+	}
+	copy(hw.VP[:], p.Payload[28:45])
+	return hw, nil
+}
@@ -0,0 +1,290 @@
+package ubx
+
+import (
+	"fmt"
+	"time"
+)
+
+// Message IDs of the NAV class.
+const (
+	IDNavStatus = 0x03
+	IDNavPVT    = 0x07
+	IDNavSat    = 0x35
+)
+
+// FixType is the type of a navigation solution.
+type FixType uint8
+
+// Fix types of NAV-PVT and NAV-STATUS messages.
+const (
+	FixNone          FixType = 0
+	FixDeadReckoning FixType = 1
+	Fix2D            FixType = 2
+	Fix3D            FixType = 3
+	FixGNSSAndDR     FixType = 4
+	FixTimeOnly      FixType = 5
+)
+
+// String implements fmt.Stringer interface.
+func (f FixType) String() string {
+	switch f {
+	case FixNone:
+		return "no fix"
+	case FixDeadReckoning:
+		return "dead reckoning"
+	case Fix2D:
+		return "2D"
+	case Fix3D:
+		return "3D"
+	case FixGNSSAndDR:
+		return "GNSS+DR"
+	case FixTimeOnly:
+		return "time only"
+	default:
+		return "unknown"
+	}
+}
+
+// CarrierSolution is the status of the carrier phase range solution (RTK).
+type CarrierSolution uint8
+
+// Carrier phase solutions of NAV-PVT messages.
+const (
+	CarrierNone  CarrierSolution = 0
+	CarrierFloat CarrierSolution = 1
+	CarrierFixed CarrierSolution = 2
+)
+
+/*
+NavPVT is a navigation position velocity time solution (UBX-NAV-PVT)
+
+Angles are in degrees, distances in meters and speeds in meters per second.
+*/
+type NavPVT struct {
+	Packet
+	// GPS time of week of the navigation epoch in milliseconds.
+	ITOW uint32
+	// UTC time of the solution, valid if ValidDate and ValidTime are set.
+	Time time.Time
+	// ValidDate is set if the UTC date is valid.
+	ValidDate bool
+	// ValidTime is set if the UTC time of day is valid.
+	ValidTime bool
+	// FullyResolved is set if the UTC time of day has no seconds uncertainty.
+	FullyResolved bool
+	// Time accuracy estimate.
+	TimeAccuracy time.Duration
+	FixType      FixType
+	// GNSSFixOK is set if the fix is valid, i.e. within DOP and accuracy masks.
+	GNSSFixOK bool
+	// DiffSoln is set if differential corrections were applied.
+	DiffSoln bool
+	// Carrier phase range solution status.
+	CarrierSolution CarrierSolution
+	// Number of satellites used in the solution.
+	NumSV uint8
+	Lon   float64
+	Lat   float64
+	// Height above the ellipsoid.
+	Height float64
+	// Height above mean sea level.
+	HeightMSL float64
+	// Horizontal accuracy estimate.
+	HAcc float64
+	// Vertical accuracy estimate.
+	VAcc float64
+	// Velocity in the NED frame.
+	VelN float64
+	VelE float64
+	VelD float64
+	// Ground speed (2-D).
+	GroundSpeed float64
+	// Heading of motion (2-D).
+	HeadMotion float64
+	// Speed accuracy estimate.
+	SAcc float64
+	// Heading accuracy estimate, both motion and vehicle.
+	HeadAcc float64
+	// Position dilution of precision.
+	PDOP float64
+	// InvalidLLH is set if Lon, Lat, Height and HeightMSL are invalid.
+	InvalidLLH bool
+	// Heading of vehicle (2-D), valid if HeadVehicleValid is set.
+	HeadVehicle      float64
+	HeadVehicleValid bool
+	// Magnetic declination and its accuracy.
+	MagDec float64
+	MagAcc float64
+}
+
+func newNavPVT(p Packet) (NavPVT, error) {
+	d, err := newDecoder(p, 92, 0)
+	if err != nil {
+		return NavPVT{}, err
+	}
+
+	valid, flags := d.U1(11), d.U1(21)
+	return NavPVT{
+		Packet: p,
+		ITOW:   d.U4(0),
+		Time: time.Date(int(d.U2(4)), time.Month(d.U1(6)), int(d.U1(7)),
+			int(d.U1(8)), int(d.U1(9)), int(d.U1(10)), int(d.I4(16)), time.UTC),
+		ValidDate:        bit(valid, 0),
+		ValidTime:        bit(valid, 1),
+		FullyResolved:    bit(valid, 2),
+		TimeAccuracy:     time.Duration(d.U4(12)),
+		FixType:          FixType(d.U1(20)),
+		GNSSFixOK:        bit(flags, 0),
+		DiffSoln:         bit(flags, 1),
+		HeadVehicleValid: bit(flags, 5),
+		CarrierSolution:  CarrierSolution(flags >> 6),
+		NumSV:            d.U1(23),
+		Lon:              float64(d.I4(24)) * 1e-7,
+		Lat:              float64(d.I4(28)) * 1e-7,
+		Height:           float64(d.I4(32)) * 1e-3,
+		HeightMSL:        float64(d.I4(36)) * 1e-3,
+		HAcc:             float64(d.U4(40)) * 1e-3,
+		VAcc:             float64(d.U4(44)) * 1e-3,
+		VelN:             float64(d.I4(48)) * 1e-3,
+		VelE:             float64(d.I4(52)) * 1e-3,
+		VelD:             float64(d.I4(56)) * 1e-3,
+		GroundSpeed:      float64(d.I4(60)) * 1e-3,
+		HeadMotion:       float64(d.I4(64)) * 1e-5,
+		SAcc:             float64(d.U4(68)) * 1e-3,
+		HeadAcc:          float64(d.U4(72)) * 1e-5,
+		PDOP:             float64(d.U2(76)) * 1e-2,
+		InvalidLLH:       bit(d.U2(78), 0),
+		HeadVehicle:      float64(d.I4(84)) * 1e-5,
+		MagDec:           float64(d.I2(88)) * 1e-2,
+		MagAcc:           float64(d.U2(90)) * 1e-2,
+	}, nil
+}
+
+// SatelliteHealth is the health of a satellite.
+type SatelliteHealth uint8
+
+// Satellite health values of NAV-SAT messages.
+const (
+	HealthUnknown   SatelliteHealth = 0
+	HealthHealthy   SatelliteHealth = 1
+	HealthUnhealthy SatelliteHealth = 2
+)
+
+// SatelliteInfo describes a satellite of a NAV-SAT message.
+type SatelliteInfo struct {
+	GNSSID GNSSID
+	SVID   uint8
+	// Carrier to noise ratio in dBHz.
+	CNo uint8
+	// Elevation in degrees, from -90 to 90.
+	Elevation int8
+	// Azimuth in degrees, from 0 to 360.
+	Azimuth int16
+	// Pseudorange residual in meters.
+	PRRes float64
+	// Signal quality indicator, from 0 (no signal) to 7 (code and carrier locked and time synchronized).
+	Quality uint8
+	// Used is set if the satellite is used for navigation.
+	Used   bool
+	Health SatelliteHealth
+	// DiffCorr is set if differential correction data is available for the satellite.
+	DiffCorr bool
+	// Orbit information source, from 0 (none) to 7 (other).
+	OrbitSource uint8
+	// EphAvail and AlmAvail are set if ephemeris and almanac are available.
+	EphAvail bool
+	AlmAvail bool
+}
+
+// NavSat is a satellite information message (UBX-NAV-SAT).
+type NavSat struct {
+	Packet
+	// GPS time of week of the navigation epoch in milliseconds.
+	ITOW uint32
+	// Message version.
+	Version    uint8
+	Satellites []SatelliteInfo
+}
+
+func newNavSat(p Packet) (NavSat, error) {
+	d, err := newDecoder(p, 8, 12)
+	if err != nil {
+		return NavSat{}, err
+	}
+	num := int(d.U1(5))
+	if len(p.Payload) != 8+12*num {
+		return NavSat{}, fmt.Errorf("%w: %s: payload length %d for %d satellites", ErrInvalidPacket, p, len(p.Payload), num)
+	}
+
+	sat := NavSat{Packet: p, ITOW: d.U4(0), Version: d.U1(4), Satellites: make([]SatelliteInfo, num)}
+	for i := range sat.Satellites {
+		off := 8 + 12*i
+		flags := d.U4(off + 8)
+		sat.Satellites[i] = SatelliteInfo{
+			GNSSID:      GNSSID(d.U1(off)),
+			SVID:        d.U1(off + 1),
+			CNo:         d.U1(off + 2),
+			Elevation:   d.I1(off + 3),
+			Azimuth:     d.I2(off + 4),
+			PRRes:       float64(d.I2(off+6)) * 0.1,
+			Quality:     uint8(flags & 0x7),
+			Used:        bit(flags, 3),
+			Health:      SatelliteHealth(flags >> 4 & 0x3),
+			DiffCorr:    bit(flags, 6),
+			OrbitSource: uint8(flags >> 8 & 0x7),
+			EphAvail:    bit(flags, 11),
+			AlmAvail:    bit(flags, 12),
+		}
+	}
+	return sat, nil
+}
+
+// UsedCount returns the number of satellites used for navigation.
+func (n NavSat) UsedCount() int {
+	used := 0
+	for _, s := range n.Satellites {
+		if s.Used {
+			used++
+		}
+	}
+	return used
+}
+
+// NavStatus is a receiver navigation status message (UBX-NAV-STATUS).
+type NavStatus struct {
+	Packet
+	// GPS time of week of the navigation epoch in milliseconds.
+	ITOW    uint32
+	FixType FixType
+	// GPSFixOK is set if the fix is valid, i.e. within DOP and accuracy masks.
+	GPSFixOK bool
+	// DiffSoln is set if differential corrections were applied.
+	DiffSoln bool
+	// WeekSet and TOWSet are set if the GPS week number and time of week are valid.
+	WeekSet bool
+	TOWSet  bool
+	// Time to first fix.
+	TTFF time.Duration
+	// Time since startup or reset.
+	MSSS time.Duration
+}
+
+func newNavStatus(p Packet) (NavStatus, error) {
+	d, err := newDecoder(p, 16, 0)
+	if err != nil {
+		return NavStatus{}, err
+	}
+
+	flags := d.U1(5)
+	return NavStatus{
+		Packet:   p,
+		ITOW:     d.U4(0),
+		FixType:  FixType(d.U1(4)),
+		GPSFixOK: bit(flags, 0),
+		DiffSoln: bit(flags, 1),
+		WeekSet:  bit(flags, 2),
+		TOWSet:   bit(flags, 3),
+		TTFF:     time.Duration(d.U4(8)) * time.Millisecond,
+		MSSS:     time.Duration(d.U4(12)) * time.Millisecond,
+	}, nil
+}
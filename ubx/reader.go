@@ -0,0 +1,97 @@
+package ubx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+/*
+Reader frames UBX packets out of a byte stream, such as gpsd's super-raw output
+or a receiver log recorded with `gpspipe -R`
+
+Bytes that are not part of a UBX packet with a valid checksum, e.g. interleaved
+NMEA sentences, are skipped.
+*/
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, HeaderLen+MaxFramePayloadLen+ChecksumLen)}
+}
+
+// Next reads the next packet. It returns io.EOF once the stream ends:
+// a packet cut short by the end of the stream is skipped.
+func (r *Reader) Next() (Packet, error) {
+	for n := HeaderLen; ; {
+		data, err := r.r.Peek(n)
+		if len(data) == 0 {
+			return Packet{}, err
+		}
+
+		m, ferr := Frame(data)
+		switch {
+		case ferr == nil:
+			p, err := ParsePacket(data[:m])
+			_, _ = r.r.Discard(m)
+			return p, err
+		case ferr == ErrIncomplete && err == nil:
+			n = m
+			continue
+		case ferr == ErrIncomplete && err != io.EOF:
+			return Packet{}, err
+		}
+
+		// A false sync or the end of the stream within a packet, look for the next sync.
+		_, _ = r.r.Discard(1)
+		n = HeaderLen
+	}
+}
+
+/*
+Frame returns the length of the UBX packet at the start of data, which holds the next
+bytes of a stream. It checks the sync bytes, the class and the length in the header
+before the checksum, so that a false sync is told apart as soon as possible.
+
+If data is the start of a possible packet, Frame returns ErrIncomplete and the length
+data must have for the packet to be framed: HeaderLen until the header is complete.
+Any other error means that data does not start with a packet, and framing resumes
+at the next byte.
+*/
+func Frame(data []byte) (int, error) {
+	switch {
+	case len(data) > 0 && data[0] != Sync1, len(data) > 1 && data[1] != Sync2, len(data) > 2 && !knownClass(data[2]):
+		return 0, fmt.Errorf("%w: % x", ErrInvalidPacket, head(data))
+	case len(data) < HeaderLen:
+		return HeaderLen, ErrIncomplete
+	}
+
+	length := int(binary.LittleEndian.Uint16(data[4:]))
+	if length > MaxFramePayloadLen {
+		return 0, fmt.Errorf("%w: payload length %d", ErrInvalidPacket, length)
+	}
+	n := HeaderLen + length + ChecksumLen
+	if len(data) < n {
+		return n, ErrIncomplete
+	}
+
+	a, b := Checksum(data[2 : n-ChecksumLen])
+	if a != data[n-2] || b != data[n-1] {
+		return 0, fmt.Errorf("%w: got %02x%02x, want %02x%02x", ErrChecksum, data[n-2], data[n-1], a, b)
+	}
+	return n, nil
+}
+
+// knownClass reports whether c is a UBX message class: NAV, RXM, INF, ACK, CFG, UPD, MON,
+// AID, TIM, ESF, MGA, LOG, SEC, HNR or NAV2.
+func knownClass(c byte) bool {
+	switch c {
+	case 0x01, 0x02, 0x04, 0x05, 0x06, 0x09, 0x0a, 0x0b, 0x0d, 0x10, 0x13, 0x21, 0x27, 0x28, 0x29:
+		return true
+	default:
+		return false
+	}
+}
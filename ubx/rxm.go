@@ -0,0 +1,106 @@
+package ubx
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Message IDs of the RXM class.
+const (
+	IDRxmRawx = 0x15
+)
+
+// Measurement is a pseudorange, carrier phase and Doppler measurement of an RXM-RAWX message.
+type Measurement struct {
+	// Pseudorange in meters, valid if PRValid is set.
+	PRMes float64
+	// Carrier phase in cycles, valid if CPValid is set.
+	CPMes float64
+	// Doppler in Hz, positive for approaching satellites.
+	DoMes  float64
+	GNSSID GNSSID
+	SVID   uint8
+	// Signal identifier within the constellation.
+	SigID uint8
+	// GLONASS frequency slot + 7, from 0 to 13.
+	FreqID uint8
+	// Carrier phase locktime.
+	Locktime time.Duration
+	// Carrier to noise ratio in dBHz.
+	CNo uint8
+	// Estimated standard deviations of the pseudorange in meters,
+	// of the carrier phase in cycles and of the Doppler in Hz.
+	PRStdev float64
+	CPStdev float64
+	DoStdev float64
+	// PRValid and CPValid are set if the pseudorange and the carrier phase are valid.
+	PRValid bool
+	CPValid bool
+	// HalfCyc is set if the half cycle is valid and SubHalfCyc if it was subtracted from the phase.
+	HalfCyc    bool
+	SubHalfCyc bool
+}
+
+// RxmRawx is a multi-GNSS raw measurement message (UBX-RXM-RAWX).
+type RxmRawx struct {
+	Packet
+	// Receiver time of week of the measurements in seconds.
+	RcvTOW float64
+	// GPS week number.
+	Week uint16
+	// GPS leap seconds, valid if LeapSecKnown is set.
+	LeapS        int8
+	LeapSecKnown bool
+	// ClkReset is set if the receiver clock was reset.
+	ClkReset bool
+	// Message version.
+	Version      uint8
+	Measurements []Measurement
+}
+
+func newRxmRawx(p Packet) (RxmRawx, error) {
+	d, err := newDecoder(p, 16, 32)
+	if err != nil {
+		return RxmRawx{}, err
+	}
+	num := int(d.U1(11))
+	if len(p.Payload) != 16+32*num {
+		return RxmRawx{}, fmt.Errorf("%w: %s: payload length %d for %d measurements", ErrInvalidPacket, p, len(p.Payload), num)
+	}
+
+	recStat := d.U1(12)
+	raw := RxmRawx{
+		Packet:       p,
+		RcvTOW:       d.R8(0),
+		Week:         d.U2(8),
+		LeapS:        d.I1(10),
+		LeapSecKnown: bit(recStat, 0),
+		ClkReset:     bit(recStat, 1),
+		Version:      d.U1(13),
+		Measurements: make([]Measurement, num),
+	}
+	for i := range raw.Measurements {
+		off := 16 + 32*i
+		trkStat := d.U1(off + 30)
+		raw.Measurements[i] = Measurement{
+			PRMes:      d.R8(off),
+			CPMes:      d.R8(off + 8),
+			DoMes:      float64(d.R4(off + 16)),
+			GNSSID:     GNSSID(d.U1(off + 20)),
+			SVID:       d.U1(off + 21),
+			SigID:      d.U1(off + 22),
+			FreqID:     d.U1(off + 23),
+			Locktime:   time.Duration(d.U2(off+24)) * time.Millisecond,
+			CNo:        d.U1(off + 26),
+			PRStdev:    0.01 * math.Pow(2, float64(d.U1(off+27)&0x0f)),
+			CPStdev:    0.004 * float64(d.U1(off+28)&0x0f),
+			DoStdev:    0.002 * math.Pow(2, float64(d.U1(off+29)&0x0f)),
+			PRValid:    bit(trkStat, 0),
+			CPValid:    bit(trkStat, 1),
+			HalfCyc:    bit(trkStat, 2),
+			SubHalfCyc: bit(trkStat, 3),
+		}
+	}
+	return raw, nil
+}
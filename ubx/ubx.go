@@ -0,0 +1,191 @@
+/*
+Package ubx decodes the u-blox UBX binary protocol as relayed by gpsd in raw and super-raw watch modes.
+
+Parse validates the framing and the checksum of a packet and decodes the supported
+messages (NAV-PVT, NAV-SAT, NAV-STATUS, RXM-RAWX and MON-HW) into typed structs.
+Other messages are returned as Packet with their raw payload. Frame tells where
+a packet ends in a byte stream, and Reader uses it to frame UBX packets out of
+a stream, skipping anything in between.
+*/
+package ubx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// Sync1 and Sync2 start every packet.
+	Sync1 = 0xb5
+	Sync2 = 0x62
+	// HeaderLen is the length of the sync bytes, class, ID and length fields.
+	HeaderLen = 6
+	// ChecksumLen is the length of the checksum following the payload.
+	ChecksumLen = 2
+	// MaxPayloadLen is the largest payload the 2-byte length field can describe.
+	MaxPayloadLen = math.MaxUint16
+	// MaxFramePayloadLen is the largest payload a receiver actually sends: the one of
+	// an RXM-RAWX message with 255 measurements. Readers framing a stream take longer
+	// lengths for a false sync instead of waiting for up to 64 KiB of payload.
+	MaxFramePayloadLen = 16 + 32*255
+)
+
+// Message classes.
+const (
+	ClassNAV = 0x01
+	ClassRXM = 0x02
+	ClassMON = 0x0a
+)
+
+var (
+	// ErrInvalidPacket is returned for data that is not a well-formed UBX packet.
+	ErrInvalidPacket = errors.New("ubx: invalid packet")
+	// ErrChecksum is returned when the checksum of a packet does not match.
+	ErrChecksum = errors.New("ubx: checksum mismatch")
+	// ErrIncomplete is returned by Frame for data that may be the start of a packet.
+	ErrIncomplete = errors.New("ubx: incomplete packet")
+)
+
+// Message is implemented by Packet and by every typed message embedding it.
+type Message interface {
+	fmt.Stringer
+	// MessageClass returns the class of the message, e.g. ClassNAV.
+	MessageClass() byte
+	// MessageID returns the ID of the message within its class.
+	MessageID() byte
+}
+
+// Packet contains the information common to all messages.
+type Packet struct {
+	Class byte
+	ID    byte
+	// Payload without the header and the checksum.
+	Payload []byte
+}
+
+// MessageClass implements Message interface.
+func (p Packet) MessageClass() byte { return p.Class }
+
+// MessageID implements Message interface.
+func (p Packet) MessageID() byte { return p.ID }
+
+// String implements fmt.Stringer interface and returns the name of the message, e.g. "NAV-PVT".
+func (p Packet) String() string {
+	if name, ok := names[[2]byte{p.Class, p.ID}]; ok {
+		return name
+	}
+	return fmt.Sprintf("UBX-%02X-%02X", p.Class, p.ID)
+}
+
+// Bytes returns the complete packet, including its header and checksum.
+func (p Packet) Bytes() []byte {
+	data := make([]byte, HeaderLen, HeaderLen+len(p.Payload)+ChecksumLen)
+	data[0], data[1], data[2], data[3] = Sync1, Sync2, p.Class, p.ID
+	binary.LittleEndian.PutUint16(data[4:], uint16(len(p.Payload)))
+	data = append(data, p.Payload...)
+	a, b := Checksum(data[2:])
+	return append(data, a, b)
+}
+
+// names of the supported messages by class and ID.
+var names = map[[2]byte]string{
+	{ClassNAV, IDNavPVT}:    "NAV-PVT",
+	{ClassNAV, IDNavSat}:    "NAV-SAT",
+	{ClassNAV, IDNavStatus}: "NAV-STATUS",
+	{ClassRXM, IDRxmRawx}:   "RXM-RAWX",
+	{ClassMON, IDMonHW}:     "MON-HW",
+}
+
+// Parse validates the packet and decodes it into its typed representation.
+// Unsupported messages are returned as Packet.
+func Parse(data []byte) (Message, error) {
+	p, err := ParsePacket(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch [2]byte{p.Class, p.ID} {
+	case [2]byte{ClassNAV, IDNavPVT}:
+		return newNavPVT(p)
+	case [2]byte{ClassNAV, IDNavSat}:
+		return newNavSat(p)
+	case [2]byte{ClassNAV, IDNavStatus}:
+		return newNavStatus(p)
+	case [2]byte{ClassRXM, IDRxmRawx}:
+		return newRxmRawx(p)
+	case [2]byte{ClassMON, IDMonHW}:
+		return newMonHW(p)
+	default:
+		return p, nil
+	}
+}
+
+// ParsePacket validates the framing and checksum of the packet without decoding its payload.
+func ParsePacket(data []byte) (Packet, error) {
+	if len(data) < HeaderLen+ChecksumLen || data[0] != Sync1 || data[1] != Sync2 {
+		return Packet{}, fmt.Errorf("%w: % x", ErrInvalidPacket, head(data))
+	}
+	n := int(binary.LittleEndian.Uint16(data[4:]))
+	if len(data) != HeaderLen+n+ChecksumLen {
+		return Packet{}, fmt.Errorf("%w: length %d, want %d", ErrInvalidPacket, len(data), HeaderLen+n+ChecksumLen)
+	}
+
+	a, b := Checksum(data[2 : HeaderLen+n])
+	if a != data[HeaderLen+n] || b != data[HeaderLen+n+1] {
+		return Packet{}, fmt.Errorf("%w: got %02x%02x, want %02x%02x",
+			ErrChecksum, data[HeaderLen+n], data[HeaderLen+n+1], a, b)
+	}
+
+	payload := make([]byte, n)
+	copy(payload, data[HeaderLen:])
+	return Packet{Class: data[2], ID: data[3], Payload: payload}, nil
+}
+
+// Checksum returns the 8-bit Fletcher checksum of the class, ID, length and payload of a packet.
+func Checksum(data []byte) (a, b byte) {
+	for _, c := range data {
+		a += c
+		b += a
+	}
+	return a, b
+}
+
+// head returns up to the first HeaderLen bytes of data for error messages.
+func head(data []byte) []byte {
+	if len(data) > HeaderLen {
+		return data[:HeaderLen]
+	}
+	return data
+}
+
+// decoder reads little-endian fields of a payload.
+type decoder struct {
+	Packet
+}
+
+// newDecoder checks that the payload is at least n bytes long,
+// or n plus a multiple of the repeated block length.
+func newDecoder(p Packet, n, block int) (decoder, error) {
+	if len(p.Payload) < n || (block > 0 && (len(p.Payload)-n)%block != 0) {
+		return decoder{}, fmt.Errorf("%w: %s: payload length %d", ErrInvalidPacket, p, len(p.Payload))
+	}
+	return decoder{Packet: p}, nil
+}
+
+func (d decoder) U1(off int) uint8   { return d.Payload[off] }
+func (d decoder) I1(off int) int8    { return int8(d.Payload[off]) }
+func (d decoder) U2(off int) uint16  { return binary.LittleEndian.Uint16(d.Payload[off:]) }
+func (d decoder) I2(off int) int16   { return int16(d.U2(off)) }
+func (d decoder) U4(off int) uint32  { return binary.LittleEndian.Uint32(d.Payload[off:]) }
+func (d decoder) I4(off int) int32   { return int32(d.U4(off)) }
+func (d decoder) R4(off int) float32 { return math.Float32frombits(d.U4(off)) }
+func (d decoder) R8(off int) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(d.Payload[off:]))
+}
+
+// bit returns whether the n-th bit of the flags is set.
+func bit[T ~uint8 | ~uint16 | ~uint32](flags T, n int) bool {
+	return flags&(1<<n) != 0
+}
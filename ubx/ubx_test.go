@@ -0,0 +1,247 @@
+package ubx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Sample NAV-PVT, NAV-SAT and RXM-RAWX packets of an RTK fixed receiver.
+const (
+	navPVTHex = "b56201075c00502a5116e807050e0a0d143715000000c01dfeff0383ea12a7cb0305d6242f1c9c9f07001ce406000e0000" +
+		"00150000000c000000fbffffff030000000d000000f85223005000000087d6120070000000000000000000000000000000f4d4"
+	navSatHex  = "b56201352000502a511601020000000c2d437b00f9ff1f19000006031f0c22010f0014000000dbb8"
+	rxmRawxHex = "b56202153000dd24060148da16410b09120101010000a4703d7e3840744139b44819c59a9a4100509ac4000c0000f4fb2d" +
+		"05030407005e09"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestParseNavPVT(t *testing.T) {
+	m, err := Parse(mustDecode(t, navPVTHex))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	pvt, ok := m.(NavPVT)
+	if !ok {
+		t.Fatalf("Parse() = %T, want NavPVT", m)
+	}
+
+	if pvt.String() != "NAV-PVT" || pvt.ITOW != 374418000 || pvt.NumSV != 18 {
+		t.Errorf("got %s, iTOW %d, numSV %d", pvt, pvt.ITOW, pvt.NumSV)
+	}
+	if want := time.Date(2024, 5, 14, 10, 13, 20, -123456, time.UTC); !pvt.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", pvt.Time, want)
+	}
+	if !pvt.ValidDate || !pvt.ValidTime || !pvt.FullyResolved || pvt.TimeAccuracy != 21 {
+		t.Errorf("validity = %v %v %v, tAcc %v", pvt.ValidDate, pvt.ValidTime, pvt.FullyResolved, pvt.TimeAccuracy)
+	}
+	if pvt.FixType != Fix3D || !pvt.GNSSFixOK || !pvt.DiffSoln || pvt.CarrierSolution != CarrierFixed {
+		t.Errorf("fix = %v, ok %v, diff %v, carrier %v", pvt.FixType, pvt.GNSSFixOK, pvt.DiffSoln, pvt.CarrierSolution)
+	}
+
+	floats := []struct {
+		name      string
+		got, want float64
+	}{
+		{"Lon", pvt.Lon, 8.4134823},
+		{"Lat", pvt.Lat, 47.285167},
+		{"Height", pvt.Height, 499.612},
+		{"HeightMSL", pvt.HeightMSL, 451.612},
+		{"HAcc", pvt.HAcc, 0.014},
+		{"VAcc", pvt.VAcc, 0.021},
+		{"VelN", pvt.VelN, 0.012},
+		{"VelE", pvt.VelE, -0.005},
+		{"VelD", pvt.VelD, 0.003},
+		{"GroundSpeed", pvt.GroundSpeed, 0.013},
+		{"HeadMotion", pvt.HeadMotion, 23.15},
+		{"SAcc", pvt.SAcc, 0.08},
+		{"HeadAcc", pvt.HeadAcc, 12.34567},
+		{"PDOP", pvt.PDOP, 1.12},
+	}
+	for _, f := range floats {
+		if !near(f.got, f.want) {
+			t.Errorf("%s = %v, want %v", f.name, f.got, f.want)
+		}
+	}
+}
+
+func TestParseNavSat(t *testing.T) {
+	m, err := Parse(mustDecode(t, navSatHex))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	sat, ok := m.(NavSat)
+	if !ok {
+		t.Fatalf("Parse() = %T, want NavSat", m)
+	}
+
+	if sat.ITOW != 374418000 || sat.Version != 1 || len(sat.Satellites) != 2 {
+		t.Fatalf("iTOW %d, version %d, %d satellites", sat.ITOW, sat.Version, len(sat.Satellites))
+	}
+	want := []SatelliteInfo{
+		{
+			GNSSID: GNSSGPS, SVID: 12, CNo: 45, Elevation: 67, Azimuth: 123,
+			Quality: 7, Used: true, Health: HealthHealthy, OrbitSource: 1, EphAvail: true, AlmAvail: true,
+		},
+		{GNSSID: GNSSGLONASS, SVID: 3, CNo: 31, Elevation: 12, Azimuth: 290, Quality: 4, Health: HealthHealthy},
+	}
+	wantPRRes := []float64{-0.7, 1.5}
+	for i := range sat.Satellites {
+		got := sat.Satellites[i]
+		if !near(got.PRRes, wantPRRes[i]) {
+			t.Errorf("Satellites[%d].PRRes = %v, want %v", i, got.PRRes, wantPRRes[i])
+		}
+		got.PRRes = 0
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Satellites[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if sat.UsedCount() != 1 {
+		t.Errorf("UsedCount() = %d, want 1", sat.UsedCount())
+	}
+}
+
+func TestParseRxmRawx(t *testing.T) {
+	m, err := Parse(mustDecode(t, rxmRawxHex))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	raw, ok := m.(RxmRawx)
+	if !ok {
+		t.Fatalf("Parse() = %T, want RxmRawx", m)
+	}
+
+	if !near(raw.RcvTOW, 374418.001) || raw.Week != 2315 || raw.LeapS != 18 || !raw.LeapSecKnown || raw.ClkReset || raw.Version != 1 {
+		t.Errorf("got %+v", raw)
+	}
+	if len(raw.Measurements) != 1 {
+		t.Fatalf("%d measurements, want 1", len(raw.Measurements))
+	}
+	meas := raw.Measurements[0]
+	if !near(meas.PRMes, 21234567.89) || !near(meas.CPMes, 111587654.321) || meas.DoMes != -1234.5 {
+		t.Errorf("pr %v, cp %v, doppler %v", meas.PRMes, meas.CPMes, meas.DoMes)
+	}
+	if !near(meas.PRStdev, 0.32) || !near(meas.CPStdev, 0.012) || !near(meas.DoStdev, 0.032) {
+		t.Errorf("stdev pr %v, cp %v, doppler %v", meas.PRStdev, meas.CPStdev, meas.DoStdev)
+	}
+	if meas.GNSSID != GNSSGPS || meas.SVID != 12 || meas.Locktime != 64500*time.Millisecond || meas.CNo != 45 {
+		t.Errorf("gnss %v, sv %d, locktime %v, cno %d", meas.GNSSID, meas.SVID, meas.Locktime, meas.CNo)
+	}
+	if !meas.PRValid || !meas.CPValid || !meas.HalfCyc || meas.SubHalfCyc {
+		t.Errorf("trkStat pr %v, cp %v, half %v, sub half %v", meas.PRValid, meas.CPValid, meas.HalfCyc, meas.SubHalfCyc)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	pvt := mustDecode(t, navPVTHex)
+	corrupt := append([]byte(nil), pvt...)
+	corrupt[10] ^= 0xff
+	short := Packet{Class: ClassNAV, ID: IDNavPVT, Payload: make([]byte, 84)}.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"checksum", corrupt, ErrChecksum},
+		{"truncated", pvt[:len(pvt)-1], ErrInvalidPacket},
+		{"no sync", pvt[1:], ErrInvalidPacket},
+		{"short payload", short, ErrInvalidPacket},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	ack := Packet{Class: 0x05, ID: 0x01, Payload: []byte{0x06, 0x8a}}
+	m, err := Parse(ack.Bytes())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(m, ack) || m.String() != "UBX-05-01" {
+		t.Errorf("Parse() = %#v (%s), want %#v", m, m, ack)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	pvt := mustDecode(t, navPVTHex)
+	oversized := []byte{Sync1, Sync2, ClassNAV, IDNavPVT, 0xff, 0xff}
+
+	tests := []struct {
+		name string
+		data []byte
+		n    int
+		err  error
+	}{
+		{"packet", pvt, len(pvt), nil},
+		{"trailing data", append(append([]byte(nil), pvt...), '$'), len(pvt), nil},
+		{"sync only", pvt[:2], HeaderLen, ErrIncomplete},
+		{"header only", pvt[:HeaderLen], len(pvt), ErrIncomplete},
+		{"not a sync", []byte("$GPGGA"), 0, ErrInvalidPacket},
+		{"unknown class", []byte{Sync1, Sync2, 0x77, 0x01, 0x00, 0x00}, 0, ErrInvalidPacket},
+		{"oversized", oversized, 0, ErrInvalidPacket},
+		{"checksum", append(append([]byte(nil), pvt[:len(pvt)-1]...), 0), 0, ErrChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Frame(tt.data)
+			if n != tt.n || !errors.Is(err, tt.err) {
+				t.Errorf("Frame() = %d, %v, want %d, %v", n, err, tt.n, tt.err)
+			}
+		})
+	}
+}
+
+func TestReader(t *testing.T) {
+	pvt, sat, raw := mustDecode(t, navPVTHex), mustDecode(t, navSatHex), mustDecode(t, rxmRawxHex)
+
+	var stream bytes.Buffer
+	stream.WriteString("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n")
+	stream.Write(pvt)
+	// A false sync inside NMEA data, followed by a packet.
+	stream.Write([]byte{Sync1, Sync2, ClassNAV})
+	stream.WriteString("\r\n")
+	stream.Write(sat)
+	// A corrupted packet is skipped.
+	stream.Write(append(append([]byte(nil), pvt[:len(pvt)-1]...), 0))
+	stream.Write(raw)
+	// A false sync and a packet cut short by the end of the stream.
+	stream.Write([]byte{Sync1, Sync2, ClassNAV, IDNavPVT, 0x5c})
+	stream.Write(pvt[:40])
+
+	r := NewReader(&stream)
+	for _, want := range [][]byte{pvt, sat, raw} {
+		p, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if got := p.Bytes(); !bytes.Equal(got, want) {
+			t.Errorf("Next() = % x, want % x", got, want)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,103 @@
+package gpsd
+
+import (
+	"context"
+	"encoding/json"
+)
+
+/*
+WatchOptions is a typed payload of the WATCH command
+
+It describes the complete watcher policy of the session: all flags are always sent,
+so switching e.g. from JSON to NMEA output disables JSON at the same time.
+
+example:
+
+	?WATCH={"enable":true,"json":true,"nmea":false,"raw":0,"scaled":false,
+		"split24":false,"pps":true,"timing":false,"device":"/dev/ttyUSB0"};
+*/
+type WatchOptions struct {
+	// Enable or disable watcher mode.
+	Enable bool `json:"enable"`
+	// Enable or disable dumping of JSON reports.
+	JSON bool `json:"json"`
+	// Enable or disable dumping of binary packets as pseudo-NMEA.
+	NMEA bool `json:"nmea"`
+	// Controls raw mode: 1 dumps binary packets as hex, 2 passes them through as they are.
+	Raw int `json:"raw"`
+	// If true, apply scaling divisors to output before dumping.
+	Scaled bool `json:"scaled"`
+	// If true, aggregate AIS type24 sentence parts.
+	Split24 bool `json:"split24"`
+	// If true, emit the TOFF JSON message on each cycle and a PPS JSON message
+	// when the device issues 1PPS.
+	PPS bool `json:"pps"`
+	// If true, report timing information.
+	Timing bool `json:"timing"`
+	// If present, enable watching only of the specified device rather than all devices.
+	Device string `json:"device,omitempty"`
+	// URL of the remote daemon reporting the watch set.
+	Remote string `json:"remote,omitempty"`
+}
+
+// command returns the WATCH command with the options as its payload.
+func (o WatchOptions) command() string {
+	// Marshalling a struct of plain fields can't fail.
+	payload, _ := json.Marshal(o)
+	return WatchCommand + "=" + string(payload)
+}
+
+// apply returns the options with the flags of a Watch param object set.
+// Keys that are not flags of WatchOptions are ignored.
+func (o WatchOptions) apply(flags map[string]bool) WatchOptions {
+	for key, v := range flags {
+		switch key {
+		case "enable":
+			o.Enable = v
+		case "json":
+			o.JSON = v
+		case "nmea":
+			o.NMEA = v
+		case "raw":
+			o.Raw = 0
+			if v {
+				o.Raw = 1
+			}
+		case "scaled":
+			o.Scaled = v
+		case "split24":
+			o.Split24 = v
+		case "pps":
+			o.PPS = v
+		case "timing":
+			o.Timing = v
+		}
+	}
+	return o
+}
+
+// WatchReport sends the WATCH command with the given options and returns the decoded
+// WATCH response. The options are remembered: they are restored after a reconnect and
+// RunContext enables the streamed format on top of them.
+func (s *Session) WatchReport(ctx context.Context, opts WatchOptions) (*WATCHReport, error) {
+	return Request[*WATCHReport](ctx, s, s.setWatch(opts))
+}
+
+// setWatch remembers the options and returns the corresponding WATCH command.
+func (s *Session) setWatch(opts WatchOptions) string {
+	command := opts.command()
+
+	s.mu.Lock()
+	s.watchOpts = opts
+	s.watch = command
+	s.mu.Unlock()
+
+	return command
+}
+
+// watchOptions returns the options last sent with WATCH.
+func (s *Session) watchOptions() WatchOptions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watchOpts
+}